@@ -0,0 +1,103 @@
+package hue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxConcurrency is the number of concurrent requests SetStates sends when the bridge has no MaxConcurrency set.
+const DefaultMaxConcurrency = 4
+
+// StateErrors is returned by SetStates when state changes failed for one or more lights.
+// It maps the id of each failed light to the error for that light.
+type StateErrors map[string]error
+
+// Error returns the errors for all failed lights, ordered by light id.
+func (e StateErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, lightID := range e.LightIDs() {
+		messages = append(messages, fmt.Sprintf("light %s: %v", lightID, e[lightID]))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// LightIDs returns the ids of the failed lights, sorted by their numeric ID.
+func (e StateErrors) LightIDs() []string {
+	lightIDs := make([]string, 0, len(e))
+	for lightID := range e {
+		lightIDs = append(lightIDs, lightID)
+	}
+	sort.Slice(lightIDs, func(i, j int) bool {
+		return lessNumericID(lightIDs[i], lightIDs[j])
+	})
+	return lightIDs
+}
+
+// Unwrap returns the individual errors, so errors.As can be used to find e.g. an *APIError.
+func (e StateErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, lightID := range e.LightIDs() {
+		errs = append(errs, e[lightID])
+	}
+	return errs
+}
+
+// SetStates applies a state change to each of the lights with the ids in changes.
+// The requests are sent concurrently, at most MaxConcurrency at a time.
+// All changes are attempted, when any of them fail a StateErrors holding the failed lights is returned.
+// When any of the changes is invalid, see LightStateChange.Validate, none of them are sent.
+func (b *Bridge) SetStates(changes map[string]LightStateChange) error {
+	return b.SetStatesContext(context.Background(), changes)
+}
+
+// SetStatesContext is like SetStates, the requests are bound to the given context.
+func (b *Bridge) SetStatesContext(ctx context.Context, changes map[string]LightStateChange) error {
+	stateErrors := StateErrors{}
+	for lightID, change := range changes {
+		if err := change.Validate(); err != nil {
+			stateErrors[lightID] = err
+		}
+	}
+	if len(stateErrors) > 0 {
+		return stateErrors
+	}
+
+	workers := b.MaxConcurrency
+	if workers <= 0 {
+		workers = DefaultMaxConcurrency
+	}
+	if workers > len(changes) {
+		workers = len(changes)
+	}
+
+	lightIDs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for lightID := range lightIDs {
+				err := b.sendStateChange(ctx, b.URL()+"/lights/"+lightID+"/state", changes[lightID])
+				if err != nil {
+					mu.Lock()
+					stateErrors[lightID] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for lightID := range changes {
+		lightIDs <- lightID
+	}
+	close(lightIDs)
+	wg.Wait()
+
+	if len(stateErrors) > 0 {
+		return stateErrors
+	}
+	return nil
+}
@@ -2,32 +2,118 @@ package hue
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 type apiResponse struct {
-	Success map[string]string
-	Error   *apiResponseError `json:"error"`
+	Success interface{} `json:"success"` // usually an object, but a string for deletions
+	Error   *APIError   `json:"error"`
 }
 
-type apiResponseError struct {
-	Type        uint   `json:"type"`
-	Address     string `json:"address"`
-	Description string `json:"description"`
+// successValue returns the value for key in the success object of the response, or nil when there is none.
+func (r *apiResponse) successValue(key string) interface{} {
+	success, _ := r.Success.(map[string]interface{})
+	return success[key]
 }
 
 // Bridge represents a Hue Bridge
+//
+// A Bridge is safe for concurrent use by multiple goroutines once it is set up.
+// The exported fields must not be assigned while the Bridge is in use, change the IP, Username, ClientKey,
+// BridgeID and InsecureSkipVerify of a Bridge that is in use with SetIP, SetUsername, SetClientKey,
+// SetBridgeID and SetInsecureSkipVerify instead.
 type Bridge struct {
 	IP       string
 	Username string
+	BridgeID string // unique id of the bridge, as reported by discovery
+
+	// ClientKey is the hex encoded key used for entertainment streaming, see CreateNewUserWithClientKey.
+	ClientKey string
+
+	// HTTPClient is used for all requests to the bridge.
+	// When nil, a client with a timeout of 5 seconds is used.
+	HTTPClient *http.Client
+
+	// Timeout, when non-zero, limits the duration of requests to the bridge, for both the v1 and the v2 api.
+	// It replaces the timeout of HTTPClient and of the default clients.
+	Timeout time.Duration
+
+	// Logger, when set, receives a message for each request sent to the bridge, with the Username redacted.
+	// The zero value disables logging.
+	Logger Logger
+
+	// Middleware wraps every request sent to the bridge, including those of the v2 api and the event stream.
+	// The first middleware is the outermost, it is called first and sees the response last.
+	Middleware []Middleware
+
+	// RetryPolicy defines how requests are retried when the bridge is too busy.
+	// The zero value disables retries.
+	RetryPolicy RetryPolicy
+
+	// CommandQueue, when set, serializes the state changes for lights and groups, and the scene recalls, sent to the bridge.
+	// SetState and the methods using it block until the change has been sent.
+	CommandQueue *CommandQueue
+
+	// CacheTTL is the duration for which the lights retrieved by GetAllLights are reused,
+	// by GetAllLights itself and the methods using it, like GetLightByName and Toggle.
+	// The zero value disables caching. See InvalidateCache.
+	CacheTTL time.Duration
+
+	// MaxConcurrency limits the number of requests SetStates sends to the bridge at the same time.
+	// When 0, DefaultMaxConcurrency is used.
+	MaxConcurrency int
+
+	// InsecureSkipVerify disables verification of the bridge certificate for the v2 api.
+	// This makes the connection vulnerable to man-in-the-middle attacks, only use it when BridgeTLSConfig can't be used.
+	InsecureSkipVerify bool
+
+	mu sync.RWMutex // guards IP, Username, ClientKey, BridgeID, InsecureSkipVerify and the v2 fields once the bridge is in use
+
+	cacheMu sync.Mutex // guards cache
+	cache   lightCache // lights cached for CacheTTL
+
+	closeMu    sync.Mutex           // guards closed, closers and nextCloser
+	closed     bool                 // whether Close was called
+	closers    map[int]func() error // releases the event streams and DTLS connections, see Track
+	nextCloser int                  // key of the next registered closer
+	running    sync.WaitGroup       // tracked resources that were not released yet, waited for by Close
+
+	v2             bool           // whether the v2 api is used, see UseV2
+	v2HTTPClient   *http.Client   // client for the v2 api, verifying the bridge certificate
+	v2ClientConfig v2ClientConfig // BridgeID and InsecureSkipVerify v2HTTPClient was created for
 }
 
+// defaultHTTPClient is used for requests to a bridge that has no HTTPClient set.
+var defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
+
 // Time performs proper json unarmshalling with time.Parse(..)
 type Time time.Time
 
+// timeLayout is the layout used by the bridge for time values
+const timeLayout = "2006-01-02T15:04:05"
+
+// MarshalJSON formats the time the way the bridge does.
+// A zero time is formatted as "none", which is how the bridge represents absent time values.
+func (t Time) MarshalJSON() ([]byte, error) {
+	stdTime := time.Time(t)
+	if stdTime.IsZero() {
+		return []byte(`"none"`), nil
+	}
+	return []byte(`"` + stdTime.Format(timeLayout) + `"`), nil
+}
+
+// UnmarshalJSON parses a time value as sent by the bridge.
 func (t *Time) UnmarshalJSON(b []byte) error {
 	// check if the given []byte looks like a json string
 	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
@@ -39,10 +125,21 @@ func (t *Time) UnmarshalJSON(b []byte) error {
 		return nil
 	}
 
-	// parse the time
-	stdTime, err := time.Parse("2006-01-02T15:04:05", string(b[1:len(b)-1]))
+	value := string(b[1 : len(b)-1])
+
+	// the bridge uses "none" (and "dynamic" for some event times) when there is no actual time, leave the zero value
+	if value == "none" || value == "dynamic" {
+		return nil
+	}
+
+	// parse the time, retry with a timezone-aware layout for times with an offset suffix
+	stdTime, err := time.Parse(timeLayout, value)
 	if err != nil {
-		return err
+		var errZone error
+		stdTime, errZone = time.Parse(time.RFC3339, value)
+		if errZone != nil {
+			return err
+		}
 	}
 	*t = Time(stdTime)
 	return nil
@@ -67,99 +164,794 @@ type BridgeConfiguration struct {
 	Swversion      string `json:"swversion"`      // Software version of the bridge.
 	ProxyAddress   string `json:"proxyaddress"`   // length 0..40. IP Address of the proxy server being used. A value of “none” indicates no proxy.
 	Mac            string `json:"mac"`            // MAC address of the bridge.
+	BridgeID       string `json:"bridgeid"`       // Unique id (serial) of the bridge, stable across IP changes.
 	LinkButton     bool   `json:"linkbutton"`     // Indicates whether the link button has been pressed within the last 30 seconds.
 	IPAddress      string `json:"ipaddress"`      // IP address of the bridge.
 	Netmask        string `json:"netmask"`        // Network mask of the bridge.
 	Gateway        string `json:"gateway"`        // Gateway IP address of the bridge.
 	DHCP           bool   `json:"dhcp"`           // Whether the IP address of the bridge is obtained with DHCP.
 	PortalServices bool   `json:"portalservices"` // This indicates whether the bridge is registered to synchronize data with a portal account.
+	Timezone       string `json:"timezone"`       // Timezone of the bridge, e.g. “Europe/London”, used for the local times of schedules.
+}
+
+// BridgeConfigurationUpdate holds the modifiable configuration values for a bridge.
+// Fields that are nil are omitted from the update and are left unchanged on the bridge.
+type BridgeConfigurationUpdate struct {
+	Name         *string `json:"name,omitempty"`         // length 4..16. Name of the bridge.
+	DHCP         *bool   `json:"dhcp,omitempty"`         // Whether the IP address of the bridge is obtained with DHCP.
+	IPAddress    *string `json:"ipaddress,omitempty"`    // IP address of the bridge. Only used when DHCP is disabled.
+	Netmask      *string `json:"netmask,omitempty"`      // Network mask of the bridge. Only used when DHCP is disabled.
+	Gateway      *string `json:"gateway,omitempty"`      // Gateway IP address of the bridge. Only used when DHCP is disabled.
+	ProxyAddress *string `json:"proxyaddress,omitempty"` // length 0..40. IP Address of the proxy server to use. A value of “none” indicates no proxy.
+	ProxyPort    *uint16 `json:"proxyport,omitempty"`    // Port of the proxy server to use. If set to 0 then a proxy is not being used.
+	Timezone     *string `json:"timezone,omitempty"`     // Timezone of the bridge, one of the values returned by GetTimezones.
+}
+
+// MutableUpdate returns an update holding the writable values of the configuration, to fetch, modify and write back
+// the configuration with SetConfiguration. Read-only values like Mac and Swversion are never included.
+// The static network settings are only included when DHCP is disabled, as the bridge ignores them otherwise.
+func (c *BridgeConfiguration) MutableUpdate() BridgeConfigurationUpdate {
+	name := c.Name
+	dhcp := c.DHCP
+	proxyAddress := c.ProxyAddress
+	proxyPort := c.Proxyport
+	update := BridgeConfigurationUpdate{
+		Name:         &name,
+		DHCP:         &dhcp,
+		ProxyAddress: &proxyAddress,
+		ProxyPort:    &proxyPort,
+	}
+	if len(c.Timezone) > 0 {
+		timezone := c.Timezone
+		update.Timezone = &timezone
+	}
+	if !c.DHCP {
+		ipAddress := c.IPAddress
+		netmask := c.Netmask
+		gateway := c.Gateway
+		update.IPAddress = &ipAddress
+		update.Netmask = &netmask
+		update.Gateway = &gateway
+	}
+	return update
 }
 
-// NewBridge creates a new Bridge instance with given IP address
-func NewBridge(IP string) *Bridge {
+// NewBridge creates a new Bridge instance with given IP address.
+// The options are applied in order, e.g. NewBridge(ip, WithUsername(username), WithTimeout(time.Second)).
+func NewBridge(IP string, opts ...BridgeOption) *Bridge {
 	b := &Bridge{
 		IP: IP,
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
 	return b
 }
 
+// NewBridgeValidated creates a new Bridge instance with given IP address, like NewBridge.
+// An error is returned when ip is not a valid IP address or a resolvable hostname, optionally with a port.
+func NewBridgeValidated(ip string, opts ...BridgeOption) (*Bridge, error) {
+	host := ip
+	if h, _, err := net.SplitHostPort(ip); err == nil {
+		host = h
+	}
+	if net.ParseIP(host) == nil {
+		// a value with only digits and dots is meant as IP address, don't let the resolver interpret it
+		if strings.Trim(host, "0123456789.") == "" {
+			return nil, fmt.Errorf("invalid bridge IP address %q", ip)
+		}
+		_, err := net.LookupHost(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bridge address %q: %w", ip, err)
+		}
+	}
+	return NewBridge(ip, opts...), nil
+}
+
 // Name returns the Name of the Bridge as string
 func (b *Bridge) Name() (string, error) {
-	c, err := b.FetchConfiguration()
+	return b.NameContext(context.Background())
+}
+
+// NameContext is like Name, the request is bound to the given context.
+func (b *Bridge) NameContext(ctx context.Context) (string, error) {
+	c, err := b.FetchConfigurationContext(ctx)
 	if err != nil {
 		return "", err
 	}
 	return c.Name, nil
 }
 
-// URL returns the basic url for api requests. It includes the bridge IP and Username
+// ID returns the unique id (serial) of the bridge as reported in its configuration.
+// Discovery reports the same id, but possibly in lower case, compare them with strings.EqualFold.
+func (b *Bridge) ID() (string, error) {
+	return b.IDContext(context.Background())
+}
+
+// IDContext is like ID, the request is bound to the given context.
+func (b *Bridge) IDContext(ctx context.Context) (string, error) {
+	c, err := b.FetchConfigurationContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return c.BridgeID, nil
+}
+
+// URL returns the basic url for api requests. It includes the bridge IP and Username, see SafeURL for logging.
 func (b *Bridge) URL() string {
-	return "http://" + b.IP + "/api/" + b.Username
+	ip, username := b.credentials()
+	return "http://" + ip + "/api/" + username
+}
+
+// SafeURL returns the basic url for api requests like URL, with the Username replaced by ***.
+// Use it instead of URL when the url is logged or shown, the Username is a secret.
+func (b *Bridge) SafeURL() string {
+	ip, username := b.credentials()
+	if len(username) == 0 {
+		return "http://" + ip + "/api/"
+	}
+	return "http://" + ip + "/api/***"
+}
+
+// SetIP changes the IP of the bridge, it is safe to call while the bridge is in use.
+func (b *Bridge) SetIP(ip string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.IP = ip
+}
+
+// SetUsername changes the Username of the bridge, it is safe to call while the bridge is in use.
+func (b *Bridge) SetUsername(username string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.Username = username
+}
+
+// SetClientKey changes the ClientKey of the bridge, it is safe to call while the bridge is in use.
+func (b *Bridge) SetClientKey(clientKey string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ClientKey = clientKey
+}
+
+// Credentials returns the IP, Username and ClientKey of the bridge, it is safe to call while the bridge is in use.
+func (b *Bridge) Credentials() (ip string, username string, clientKey string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.IP, b.Username, b.ClientKey
+}
+
+// SetBridgeID changes the BridgeID of the bridge, it is safe to call while the bridge is in use.
+// Subsequent requests to the v2 api verify the bridge certificate against the new id.
+func (b *Bridge) SetBridgeID(bridgeID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.BridgeID = bridgeID
+}
+
+// SetInsecureSkipVerify changes InsecureSkipVerify of the bridge, it is safe to call while the bridge is in use.
+// Subsequent requests to the v2 api use the new setting.
+func (b *Bridge) SetInsecureSkipVerify(insecureSkipVerify bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.InsecureSkipVerify = insecureSkipVerify
+}
+
+// credentials returns the IP and Username of the bridge
+func (b *Bridge) credentials() (ip string, username string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.IP, b.Username
+}
+
+// bridgeID returns the BridgeID of the bridge
+func (b *Bridge) bridgeID() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.BridgeID
+}
+
+// clientKey returns the ClientKey of the bridge
+func (b *Bridge) clientKey() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.ClientKey
 }
 
 // CreateNewUser creates a new user at the bridge.
 // The end-user must press the link button in advance to prove physical access.
+// The deviceType must have the form “appname#devicename” with at most 40 characters, e.g. “huexample#laptop”,
+// otherwise an error is returned without contacting the bridge.
 // When the second argument (newUsername) is left emtpy, the bridge will provide a username.
 // CreateNewUser does not update the Bridge instance with the username. This must be done manually.
 func (b *Bridge) CreateNewUser(deviceType string, newUsername string) (string, error) {
-	requestData := map[string]string{"devicetype": deviceType}
+	return b.CreateNewUserContext(context.Background(), deviceType, newUsername)
+}
+
+// CreateNewUserContext is like CreateNewUser, the request is bound to the given context.
+func (b *Bridge) CreateNewUserContext(ctx context.Context, deviceType string, newUsername string) (string, error) {
+	deviceType, err := normalizeDeviceType(deviceType)
+	if err != nil {
+		return "", err
+	}
+	requestData := map[string]interface{}{"devicetype": deviceType}
 	if len(newUsername) > 0 {
 		requestData["username"] = newUsername
 	}
+	username, _, err := b.createUser(ctx, requestData)
+	return username, err
+}
 
-	// create empty buffer
-	buf := bytes.NewBuffer(nil)
+// CreateNewUserWithClientKey creates a new user on the bridge, like CreateNewUser with a username chosen by the bridge.
+// The bridge additionally generates a client key, which is required for entertainment streaming.
+// The client key is stored in ClientKey on success, the username is returned but not stored.
+func (b *Bridge) CreateNewUserWithClientKey(deviceType string) (username string, clientKey string, err error) {
+	return b.CreateNewUserWithClientKeyContext(context.Background(), deviceType)
+}
 
-	// encode requestData to buffer
-	err := json.NewEncoder(buf).Encode(requestData)
+// CreateNewUserWithClientKeyContext is like CreateNewUserWithClientKey, the request is bound to the given context.
+func (b *Bridge) CreateNewUserWithClientKeyContext(ctx context.Context, deviceType string) (username string, clientKey string, err error) {
+	deviceType, err = normalizeDeviceType(deviceType)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-
-	// do post to api
-	response, err := http.Post("http://"+b.IP+"/api", "text/json", buf)
+	username, clientKey, err = b.createUser(ctx, map[string]interface{}{
+		"devicetype":        deviceType,
+		"generateclientkey": true,
+	})
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	defer response.Body.Close()
+	if len(clientKey) == 0 {
+		return "", "", errors.New("bridge did not return a client key, it may not support entertainment streaming")
+	}
+	b.SetClientKey(clientKey)
+	return username, clientKey, nil
+}
 
-	// create and decode apiResponse
-	apiResponseSlice := make([]*apiResponse, 0, 1)
-	err = json.NewDecoder(response.Body).Decode(&apiResponseSlice)
+// ensureUserPollInterval is the interval at which EnsureUser checks whether the link button was pressed
+const ensureUserPollInterval = 2 * time.Second
+
+// EnsureUser makes sure the bridge has a working Username.
+// When Username is set, it is verified with a cheap authenticated request. Only when no Username is set,
+// or when the bridge reports it as unauthorized, a new user is created with WaitForLinkButton and stored in Username.
+// The caller should ask the end-user to press the link button when pairing is needed, e.g. when EnsureUser takes long.
+func (b *Bridge) EnsureUser(ctx context.Context, deviceType string) error {
+	_, username := b.credentials()
+	if len(username) > 0 {
+		err := b.getJSON(ctx, b.URL()+"/groups/"+AllLightsGroupID, &json.RawMessage{})
+		if err == nil {
+			return nil
+		}
+		if !IsUnauthorized(err) {
+			return err
+		}
+	}
+
+	username, err := b.WaitForLinkButton(ctx, deviceType, ensureUserPollInterval)
 	if err != nil {
-		return "", err
+		return err
 	}
-	if len(apiResponseSlice) == 0 {
-		return "", errors.New("received empty api response array")
+	b.SetUsername(username)
+	return nil
+}
+
+// maxDeviceTypeLength is the maximum length of the devicetype accepted by the bridge
+const maxDeviceTypeLength = 40
+
+// normalizeDeviceType checks that deviceType has the form “appname#devicename” expected by the bridge,
+// and returns it with whitespace around both parts removed.
+func normalizeDeviceType(deviceType string) (string, error) {
+	appName, deviceName, ok := strings.Cut(deviceType, "#")
+	appName, deviceName = strings.TrimSpace(appName), strings.TrimSpace(deviceName)
+	if !ok || len(appName) == 0 || len(deviceName) == 0 || strings.Contains(deviceName, "#") {
+		return "", fmt.Errorf("invalid device type %q, must have the form appname#devicename", deviceType)
+	}
+	deviceType = appName + "#" + deviceName
+	if len(deviceType) > maxDeviceTypeLength {
+		return "", fmt.Errorf("invalid device type %q, must not be longer than %d characters", deviceType, maxDeviceTypeLength)
+	}
+	return deviceType, nil
+}
+
+// createUser posts the request to create a user and returns the username and client key (if any) from the response.
+func (b *Bridge) createUser(ctx context.Context, requestData map[string]interface{}) (string, string, error) {
+	ip, _ := b.credentials()
+	apiResponseSlice, err := b.sendAPIRequest(ctx, "POST", "http://"+ip+"/api", requestData)
+	if err != nil {
+		return "", "", err
 	}
 	if len(apiResponseSlice) > 1 {
-		return "", errors.New("received api response array with >1 items")
+		return "", "", errors.New("received api response array with >1 items")
 	}
 
-	apiResponse := apiResponseSlice[0]
+	username, _ := apiResponseSlice[0].successValue("username").(string)
+	clientKey, _ := apiResponseSlice[0].successValue("clientkey").(string)
+	return username, clientKey, nil
+}
 
-	// check for error from bridge
-	if apiResponse.Error != nil {
-		return "", errors.New(apiResponse.Error.Description)
-	}
+// WaitForLinkButton repeatedly tries to create a new user until the end-user presses the link button on the bridge.
+// Attempts are made every pollInterval. The username provided by the bridge is returned once pairing succeeds.
+// Errors other than "link button not pressed", including an invalid deviceType (see CreateNewUser), are returned immediately.
+// When the context is cancelled before pairing succeeds, ctx.Err() is returned.
+func (b *Bridge) WaitForLinkButton(ctx context.Context, deviceType string, pollInterval time.Duration) (string, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
 
-	return apiResponse.Success["username"], nil
+	for {
+		username, err := b.CreateNewUserContext(ctx, deviceType, "")
+		if err == nil {
+			return username, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if !IsLinkButtonNotPressed(err) {
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
 
 // FetchConfiguration fetches the configuration data and returns it as *BridgeConfiguration
 func (b *Bridge) FetchConfiguration() (*BridgeConfiguration, error) {
-	response, err := http.Get(b.URL() + "/config")
+	return b.FetchConfigurationContext(context.Background())
+}
+
+// FetchConfigurationContext is like FetchConfiguration, the request is bound to the given context.
+func (b *Bridge) FetchConfigurationContext(ctx context.Context) (*BridgeConfiguration, error) {
+	bridgeConfiguration := &BridgeConfiguration{}
+	err := b.getJSON(ctx, b.URL()+"/config", bridgeConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeConfiguration, nil
+}
+
+// PublicConfig holds the configuration values a bridge reveals without authentication.
+type PublicConfig struct {
+	Name             string `json:"name"`             // name of the bridge
+	BridgeID         string `json:"bridgeid"`         // unique id (serial) of the bridge
+	ModelID          string `json:"modelid"`          // hardware model of the bridge, e.g. “BSB002”
+	Mac              string `json:"mac"`              // MAC address of the bridge
+	Swversion        string `json:"swversion"`        // software version of the bridge
+	APIVersion       string `json:"apiversion"`       // version of the api, e.g. “1.46.0”
+	DatastoreVersion string `json:"datastoreversion"` // version of the data store
+	FactoryNew       bool   `json:"factorynew"`       // whether the bridge is new from the factory, and not set up yet
+	ReplacesBridgeID string `json:"replacesbridgeid"` // id of the bridge that was replaced by this one, if any
+	StarterKitID     string `json:"starterkitid"`     // id of the starter kit the bridge was part of, if any
+}
+
+// FetchPublicConfiguration fetches the configuration values the bridge reveals without authentication.
+// It does not require a Username, so it can be used to identify a bridge before pairing.
+func (b *Bridge) FetchPublicConfiguration() (*PublicConfig, error) {
+	return b.FetchPublicConfigurationContext(context.Background())
+}
+
+// FetchPublicConfigurationContext is like FetchPublicConfiguration, the request is bound to the given context.
+func (b *Bridge) FetchPublicConfigurationContext(ctx context.Context) (*PublicConfig, error) {
+	ip, _ := b.credentials()
+	publicConfig := &PublicConfig{}
+	err := b.getJSON(ctx, "http://"+ip+"/api/config", publicConfig)
 	if err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
+	return publicConfig, nil
+}
+
+// ErrNotHueBridge is returned by Ping when the device at the IP of the bridge responds, but not like a hue bridge.
+var ErrNotHueBridge = errors.New("device does not respond like a hue bridge")
 
+// pingTimeout is the maximum time Ping waits for the bridge to respond
+const pingTimeout = 2 * time.Second
+
+// Ping checks that the bridge is reachable and that the IP still points to a hue bridge, e.g. after a DHCP change.
+// It fetches the public configuration, waiting at most 2 seconds, and does not require a Username.
+// ErrNotHueBridge is returned when the configuration does not identify a hue bridge.
+func (b *Bridge) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	publicConfig, err := b.FetchPublicConfigurationContext(ctx)
+	if err != nil {
+		var syntaxError *json.SyntaxError
+		var typeError *json.UnmarshalTypeError
+		var transportError *TransportError
+		if errors.As(err, &syntaxError) || errors.As(err, &typeError) || errors.As(err, &transportError) {
+			return fmt.Errorf("%w: %v", ErrNotHueBridge, err)
+		}
+		return err
+	}
+	if len(publicConfig.BridgeID) == 0 && len(publicConfig.ModelID) == 0 {
+		return ErrNotHueBridge
+	}
+	return nil
+}
+
+// SetConfiguration updates the configuration of the bridge with the non-nil fields in cfg.
+func (b *Bridge) SetConfiguration(cfg BridgeConfigurationUpdate) error {
+	return b.SetConfigurationContext(context.Background(), cfg)
+}
+
+// SetConfigurationContext is like SetConfiguration, the request is bound to the given context.
+func (b *Bridge) SetConfigurationContext(ctx context.Context, cfg BridgeConfigurationUpdate) error {
+	_, err := b.sendAPIRequest(ctx, "PUT", b.URL()+"/config", cfg)
+	return err
+}
+
+// Rename sets the name of the bridge. The name must have a length between 4 and 16 characters,
+// otherwise an error is returned without contacting the bridge.
+func (b *Bridge) Rename(name string) error {
+	return b.RenameContext(context.Background(), name)
+}
+
+// RenameContext is like Rename, the request is bound to the given context.
+func (b *Bridge) RenameContext(ctx context.Context, name string) error {
+	if n := utf8.RuneCountInString(name); n < 4 || n > 16 {
+		return errors.New("bridge name must have a length between 4 and 16 characters")
+	}
+	return b.SetConfigurationContext(ctx, BridgeConfigurationUpdate{Name: &name})
+}
+
+// Touchlink makes the bridge adopt the nearest powered light, also when the light is connected to another bridge.
+// This is the way to recover a light that is stuck on another network. Only lights physically close to the bridge
+// (less than about a meter) are affected. The bridge takes some seconds, search for new lights afterwards
+// with GetNewLights. An *APIError is returned when the bridge does not support touchlink.
+func (b *Bridge) Touchlink() error {
+	return b.TouchlinkContext(context.Background())
+}
+
+// TouchlinkContext is like Touchlink, the request is bound to the given context.
+func (b *Bridge) TouchlinkContext(ctx context.Context) error {
+	_, err := b.sendAPIRequest(ctx, "PUT", b.URL()+"/config", map[string]bool{"touchlink": true})
+	return err
+}
+
+// SetStaticIP disables DHCP on the bridge and sets its IPv4 address, netmask and gateway, all in a single update,
+// so the bridge is never left with a partial network configuration. The values are validated before contacting
+// the bridge: the netmask must be contiguous, and ip and gateway must be distinct host addresses in the same subnet.
+// On success the IP of the bridge is changed to ip, as the bridge moves to the new address.
+func (b *Bridge) SetStaticIP(ip, netmask, gateway string) error {
+	return b.SetStaticIPContext(context.Background(), ip, netmask, gateway)
+}
+
+// SetStaticIPContext is like SetStaticIP, the request is bound to the given context.
+func (b *Bridge) SetStaticIPContext(ctx context.Context, ip, netmask, gateway string) error {
+	err := validateStaticIP(ip, netmask, gateway)
+	if err != nil {
+		return err
+	}
+	dhcp := false
+	err = b.SetConfigurationContext(ctx, BridgeConfigurationUpdate{
+		DHCP:      &dhcp,
+		IPAddress: &ip,
+		Netmask:   &netmask,
+		Gateway:   &gateway,
+	})
+	if err != nil {
+		return err
+	}
+	b.SetIP(ip)
+	return nil
+}
+
+// validateStaticIP checks that ip and gateway are distinct IPv4 host addresses in the subnet given by netmask.
+func validateStaticIP(ip, netmask, gateway string) error {
+	hostIP := net.ParseIP(ip).To4()
+	if hostIP == nil {
+		return fmt.Errorf("invalid ip address %q, must be an IPv4 address", ip)
+	}
+	gatewayIP := net.ParseIP(gateway).To4()
+	if gatewayIP == nil {
+		return fmt.Errorf("invalid gateway %q, must be an IPv4 address", gateway)
+	}
+	maskIP := net.ParseIP(netmask).To4()
+	if maskIP == nil {
+		return fmt.Errorf("invalid netmask %q, must be an IPv4 netmask", netmask)
+	}
+	mask := net.IPMask(maskIP)
+	if ones, bits := mask.Size(); bits == 0 || ones == 0 || ones > 30 {
+		return fmt.Errorf("invalid netmask %q, must be contiguous and leave room for hosts", netmask)
+	}
+
+	subnet := &net.IPNet{IP: hostIP.Mask(mask), Mask: mask}
+	if !subnet.Contains(gatewayIP) {
+		return fmt.Errorf("gateway %s is not in the subnet %s of ip address %s", gateway, subnet, ip)
+	}
+	if hostIP.Equal(gatewayIP) {
+		return fmt.Errorf("ip address %s must differ from the gateway", ip)
+	}
+	for _, addr := range []net.IP{hostIP, gatewayIP} {
+		if isNetworkOrBroadcast(addr, mask) {
+			return fmt.Errorf("%s is the network or broadcast address of the subnet %s", addr, subnet)
+		}
+	}
+	return nil
+}
+
+// isNetworkOrBroadcast reports whether the host part of the IPv4 address is all zeros or all ones.
+func isNetworkOrBroadcast(addr net.IP, mask net.IPMask) bool {
+	allZeros, allOnes := true, true
+	for i := range addr {
+		host := addr[i] &^ mask[i]
+		if host != 0 {
+			allZeros = false
+		}
+		if host != ^mask[i] {
+			allOnes = false
+		}
+	}
+	return allZeros || allOnes
+}
+
+// GetTimezones returns the timezones supported by the bridge, e.g. “Europe/London”.
+func (b *Bridge) GetTimezones() ([]string, error) {
+	return b.GetTimezonesContext(context.Background())
+}
+
+// GetTimezonesContext is like GetTimezones, the requests are bound to the given context.
+func (b *Bridge) GetTimezonesContext(ctx context.Context) ([]string, error) {
+	timezones := make([]string, 0)
+	err := b.getJSON(ctx, b.URL()+"/info/timezones", &timezones)
+	if err == nil {
+		return timezones, nil
+	}
+	if !isAPIErrorType(err, ErrorTypeResourceNotAvailable) && !isAPIErrorType(err, ErrorTypeMethodNotAvailable) {
+		return nil, err
+	}
+
+	// newer bridge software moved the list to the capabilities
+	capabilities := struct {
+		Values []string `json:"values"`
+	}{}
+	err = b.getJSON(ctx, b.URL()+"/capabilities/timezones", &capabilities)
+	if err != nil {
+		return nil, err
+	}
+	return capabilities.Values, nil
+}
+
+// SetTimezone sets the timezone of the bridge, which defines the local time of schedules.
+// The timezone must be one of the timezones returned by GetTimezones, otherwise an error is returned
+// without changing the configuration.
+func (b *Bridge) SetTimezone(timezone string) error {
+	return b.SetTimezoneContext(context.Background(), timezone)
+}
+
+// SetTimezoneContext is like SetTimezone, the requests are bound to the given context.
+func (b *Bridge) SetTimezoneContext(ctx context.Context, timezone string) error {
+	timezones, err := b.GetTimezonesContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, supported := range timezones {
+		if supported == timezone {
+			return b.SetConfigurationContext(ctx, BridgeConfigurationUpdate{Timezone: &timezone})
+		}
+	}
+	return fmt.Errorf("timezone %q is not supported by the bridge", timezone)
+}
+
+// CheckForUpdate lets the bridge check for available software updates.
+// Poll FetchConfiguration afterwards to observe the progress in SwUpdate.UpdateState.
+func (b *Bridge) CheckForUpdate() error {
+	return b.CheckForUpdateContext(context.Background())
+}
+
+// CheckForUpdateContext is like CheckForUpdate, the request is bound to the given context.
+func (b *Bridge) CheckForUpdateContext(ctx context.Context) error {
+	update := map[string]interface{}{"swupdate": map[string]bool{"checkforupdate": true}}
+	_, err := b.sendAPIRequest(ctx, "PUT", b.URL()+"/config", update)
+	return err
+}
+
+// InstallUpdate lets the bridge install an available software update.
+// Poll FetchConfiguration afterwards to observe the progress in SwUpdate.UpdateState.
+func (b *Bridge) InstallUpdate() error {
+	return b.InstallUpdateContext(context.Background())
+}
+
+// InstallUpdateContext is like InstallUpdate, the request is bound to the given context.
+func (b *Bridge) InstallUpdateContext(ctx context.Context) error {
+	update := map[string]interface{}{"swupdate": map[string]int{"updatestate": 3}}
+	_, err := b.sendAPIRequest(ctx, "PUT", b.URL()+"/config", update)
+	return err
+}
+
+// doRequest sends a request with given method and body to the url. The request is bound to the given context.
+// The caller must close the body of the returned response.
+func (b *Bridge) doRequest(ctx context.Context, method string, url string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	response, err := b.do(clientForContext(ctx, b.httpClient()), request)
+	if err != nil {
+		// the error includes the url, which must not leak the Username
+		var urlError *neturl.Error
+		if errors.As(err, &urlError) {
+			urlError.URL = b.redact(urlError.URL)
+		}
+		b.logf("hue: %s %s: %v", method, b.redact(url), err)
+		return nil, err
+	}
+	b.logf("hue: %s %s: %s", method, b.redact(url), response.Status)
+	return response, nil
+}
+
+// httpClient returns the client to use for requests to the bridge.
+func (b *Bridge) httpClient() *http.Client {
+	if b.HTTPClient != nil {
+		return b.withTimeout(b.HTTPClient)
+	}
+	return b.withTimeout(defaultHTTPClient)
+}
+
+// withTimeout returns client, or a copy of it with the Timeout of the bridge when that is set.
+func (b *Bridge) withTimeout(client *http.Client) *http.Client {
+	if b.Timeout == 0 || client.Timeout == b.Timeout {
+		return client
+	}
+	timeoutClient := *client
+	timeoutClient.Timeout = b.Timeout
+	return &timeoutClient
+}
+
+// maxTransportErrorBody is the maximum number of body bytes included in a TransportError
+const maxTransportErrorBody = 512
+
+// checkResponse returns a *TransportError when the response does not have http status 200 OK.
+func checkResponse(response *http.Response) error {
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxTransportErrorBody))
+	return &TransportError{
+		StatusCode: response.StatusCode,
+		Body:       string(bytes.TrimSpace(body)),
+	}
+}
+
+// getJSON does a GET request to the url and decodes the json response into v.
+// When the bridge responds with an error, it is returned as *APIError.
+// When the response has a http status other than 200 OK, a *TransportError is returned.
+func (b *Bridge) getJSON(ctx context.Context, url string, v interface{}) error {
+	err := b.retry(ctx, "GET", func() error {
+		response, err := b.doRequest(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		err = checkResponse(response)
+		if err != nil {
+			return err
+		}
+		return decodeJSON(response.Body, v)
+	})
+	b.logResult("GET", url, err)
+	return err
+}
+
+// decodeJSON decodes the json response body r into v.
+// When the bridge responded with an error, it is returned as *APIError.
+func decodeJSON(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	// errors are sent as an apiResponse array, even for resources that are objects
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		apiResponseSlice := make([]*apiResponse, 0, 1)
+		if json.Unmarshal(trimmed, &apiResponseSlice) == nil {
+			for _, apiResponse := range apiResponseSlice {
+				if apiResponse != nil && apiResponse.Error != nil {
+					return apiResponse.Error
+				}
+			}
+		}
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// sendAPIRequest encodes requestData as json and sends it to the given url using the given method.
+// When requestData is nil, the request is sent without body.
+// The bridge responds with an array of apiResponse objects, which is decoded and returned.
+// A *TransportError is returned when the response has a http status other than 200 OK,
+// errors reported by the bridge are returned as described for decodeAPIResponse.
+func (b *Bridge) sendAPIRequest(ctx context.Context, method string, url string, requestData interface{}) ([]*apiResponse, error) {
+	// encode requestData, no body is sent when there is no requestData
+	var requestBody []byte
+	if requestData != nil {
+		var err error
+		requestBody, err = json.Marshal(requestData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var apiResponseSlice []*apiResponse
+	err := b.retry(ctx, method, func() error {
+		var body io.Reader
+		if requestBody != nil {
+			body = bytes.NewReader(requestBody)
+		}
+		response, err := b.doRequest(ctx, method, url, body)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		err = checkResponse(response)
+		if err != nil {
+			return err
+		}
+		apiResponseSlice, err = decodeAPIResponse(response.Body)
+		return err
+	})
+	// modifying requests may change the lights, even when an error is returned
+	b.InvalidateCache()
+	b.logResult(method, url, err)
+	if err != nil {
+		return nil, err
+	}
+	return apiResponseSlice, nil
+}
+
+// decodeAPIResponse decodes an apiResponse array from r.
+// When the bridge reports an error in one of the items, it is returned as *APIError.
+// When multiple items contain an error, all of them are returned as APIErrors.
+func decodeAPIResponse(r io.Reader) ([]*apiResponse, error) {
 	// create and decode apiResponse
-	bridgeConfiguration := &BridgeConfiguration{}
-	err = json.NewDecoder(response.Body).Decode(bridgeConfiguration)
+	apiResponseSlice := make([]*apiResponse, 0, 1)
+	err := json.NewDecoder(r).Decode(&apiResponseSlice)
 	if err != nil {
 		return nil, err
 	}
 
-	return bridgeConfiguration, nil
+	// drop null elements, so callers can use the responses without checking for nil
+	responses := apiResponseSlice[:0]
+	for _, apiResponse := range apiResponseSlice {
+		if apiResponse != nil {
+			responses = append(responses, apiResponse)
+		}
+	}
+	apiResponseSlice = responses
+	if len(apiResponseSlice) == 0 {
+		return nil, errors.New("received empty api response array")
+	}
+
+	// check for errors from bridge
+	var apiErrors APIErrors
+	for _, apiResponse := range apiResponseSlice {
+		if apiResponse.Error != nil {
+			apiErrors = append(apiErrors, apiResponse.Error)
+		}
+	}
+	switch len(apiErrors) {
+	case 0:
+	case 1:
+		return nil, apiErrors[0]
+	default:
+		return nil, apiErrors
+	}
+
+	return apiResponseSlice, nil
+}
+
+// createdID returns the id of a newly created resource from the apiResponse array returned by the bridge.
+func createdID(apiResponseSlice []*apiResponse) (string, error) {
+	for _, apiResponse := range apiResponseSlice {
+		if id, ok := apiResponse.successValue("id").(string); ok {
+			return id, nil
+		}
+	}
+	return "", errors.New("received api response without id of created resource")
 }
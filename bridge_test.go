@@ -0,0 +1,128 @@
+package hue_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GeertJohan/go.hue"
+	"github.com/GeertJohan/go.hue/huetest"
+)
+
+// testUsername is the username used with the bridges of the tests that serve raw responses instead of using huetest
+const testUsername = "testuser"
+
+// configResponder answers requests for the configuration with the given json.
+func configResponder(config string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/"+testUsername+"/config" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, config)
+	}
+}
+
+func TestFetchConfigurationUTCNone(t *testing.T) {
+	server := httptest.NewServer(configResponder(`{
+		"name": "Philips hue",
+		"utc": "none",
+		"swversion": "1940094000",
+		"whitelist": {
+			"abc": {"last use date": "none", "create date": "2019-01-02T03:04:05", "name": "app#device"}
+		}
+	}`))
+	defer server.Close()
+	b := hue.NewBridge(strings.TrimPrefix(server.URL, "http://"), hue.WithUsername(testUsername))
+
+	config, err := b.FetchConfiguration()
+	if err != nil {
+		t.Fatalf("FetchConfiguration: %v", err)
+	}
+	if config.Name != "Philips hue" || config.Swversion != "1940094000" {
+		t.Errorf("FetchConfiguration decoded name %q and swversion %q, want values after utc to be decoded", config.Name, config.Swversion)
+	}
+	if config.Utc == nil || !time.Time(*config.Utc).IsZero() {
+		t.Errorf("utc none decoded as %v, want zero time", config.Utc)
+	}
+	user := config.Whitelist["abc"]
+	if user.LastUseDate == nil || !time.Time(*user.LastUseDate).IsZero() {
+		t.Errorf("last use date none decoded as %v, want zero time", user.LastUseDate)
+	}
+	want := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	if user.CreateDate == nil || !time.Time(*user.CreateDate).Equal(want) {
+		t.Errorf("create date decoded as %v, want %v", user.CreateDate, want)
+	}
+}
+
+func TestFetchConfigurationUTCOffset(t *testing.T) {
+	server := httptest.NewServer(configResponder(`{
+		"utc": "2024-03-31T03:15:00+02:00",
+		"whitelist": {
+			"abc": {"last use date": "dynamic", "create date": "2024-03-31T01:15:00Z", "name": "app#device"}
+		}
+	}`))
+	defer server.Close()
+	b := hue.NewBridge(strings.TrimPrefix(server.URL, "http://"), hue.WithUsername(testUsername))
+
+	config, err := b.FetchConfiguration()
+	if err != nil {
+		t.Fatalf("FetchConfiguration: %v", err)
+	}
+	want := time.Date(2024, 3, 31, 1, 15, 0, 0, time.UTC)
+	if config.Utc == nil || !time.Time(*config.Utc).Equal(want) {
+		t.Errorf("utc with offset decoded as %v, want %v", config.Utc, want)
+	}
+	user := config.Whitelist["abc"]
+	if user.CreateDate == nil || !time.Time(*user.CreateDate).Equal(want) {
+		t.Errorf("create date in UTC decoded as %v, want %v", user.CreateDate, want)
+	}
+	if user.LastUseDate == nil || !time.Time(*user.LastUseDate).IsZero() {
+		t.Errorf("last use date dynamic decoded as %v, want zero time", user.LastUseDate)
+	}
+}
+
+func TestNullAPIResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/"+testUsername+"/lights/3" {
+			io.WriteString(w, `[null,{"success":"/lights/3 deleted"}]`)
+			return
+		}
+		io.WriteString(w, `[null]`)
+	}))
+	defer server.Close()
+	b := hue.NewBridge(strings.TrimPrefix(server.URL, "http://"), hue.WithUsername(testUsername))
+
+	err := b.DeleteLight("3")
+	if err != nil {
+		t.Errorf("DeleteLight with a null item before the success item: %v", err)
+	}
+
+	err = b.DeleteLight("4")
+	if err == nil {
+		t.Error("DeleteLight with only a null item returned no error")
+	}
+}
+
+func TestRenameCountsCharacters(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	b := srv.Bridge()
+
+	// 16 characters, but 18 bytes
+	err := b.Rename("Küche Ätherlampe")
+	if err != nil {
+		t.Errorf("Rename with a name of 16 characters: %v", err)
+	}
+	// 6 bytes, but 3 characters
+	err = b.Rename("äöü")
+	if err == nil {
+		t.Error("Rename with a name of 3 characters returned no error")
+	}
+	if commands := srv.CommandsTo("/config"); len(commands) != 1 {
+		t.Errorf("Rename sent %d commands to /config, want 1", len(commands))
+	}
+}
@@ -0,0 +1,45 @@
+package hue
+
+import (
+	"time"
+)
+
+// lightCache holds the lights retrieved by GetAllLights, to be reused while they are younger than Bridge.CacheTTL
+type lightCache struct {
+	lights  map[string]*LightAttributes // attributes by light id, nil when nothing is cached
+	fetched time.Time                   // time at which the lights were retrieved
+}
+
+// InvalidateCache drops the cached lights, so the next lookup retrieves them from the bridge.
+// The cache is invalidated automatically when a modifying request is sent through the bridge,
+// it only needs to be invalidated when the lights were changed by other means, e.g. the app.
+func (b *Bridge) InvalidateCache() {
+	b.cacheMu.Lock()
+	defer b.cacheMu.Unlock()
+	b.cache = lightCache{}
+}
+
+// cachedLights returns the cached lights and the time they were retrieved,
+// the boolean is false when caching is disabled or the cached lights are older than CacheTTL.
+func (b *Bridge) cachedLights() (map[string]*LightAttributes, time.Time, bool) {
+	if b.CacheTTL <= 0 {
+		return nil, time.Time{}, false
+	}
+	b.cacheMu.Lock()
+	defer b.cacheMu.Unlock()
+	if b.cache.lights == nil || time.Since(b.cache.fetched) > b.CacheTTL {
+		return nil, time.Time{}, false
+	}
+	return b.cache.lights, b.cache.fetched, true
+}
+
+// cacheLights stores the lights that were retrieved at the given time, when caching is enabled.
+// The map must not be modified afterwards.
+func (b *Bridge) cacheLights(lights map[string]*LightAttributes, fetched time.Time) {
+	if b.CacheTTL <= 0 {
+		return
+	}
+	b.cacheMu.Lock()
+	defer b.cacheMu.Unlock()
+	b.cache = lightCache{lights: lights, fetched: fetched}
+}
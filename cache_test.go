@@ -0,0 +1,94 @@
+package hue_test
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GeertJohan/go.hue"
+	"github.com/GeertJohan/go.hue/huetest"
+)
+
+// countingTransport counts the requests listing the lights in getLights.
+type countingTransport struct {
+	path      string
+	getLights *int32
+}
+
+func (c countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method == "GET" && request.URL.Path == c.path {
+		atomic.AddInt32(c.getLights, 1)
+	}
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// newCachingBridge returns a fake bridge with a single light named “Lamp”, and a bridge with CacheTTL set
+// that counts the requests listing the lights in getLights.
+func newCachingBridge(t *testing.T, getLights *int32) (*huetest.Server, *hue.Bridge) {
+	t.Helper()
+	srv := huetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetLight("1", hue.LightAttributes{Name: "Lamp", State: hue.LightState{On: false, Reachable: true}})
+
+	b := srv.Bridge()
+	b.CacheTTL = time.Minute
+	b.HTTPClient = &http.Client{Transport: countingTransport{path: "/api/" + srv.Username() + "/lights", getLights: getLights}}
+	return srv, b
+}
+
+func TestCacheHit(t *testing.T) {
+	var getLights int32
+	_, b := newCachingBridge(t, &getLights)
+
+	for i := 0; i < 2; i++ {
+		light, err := b.GetLightByName("Lamp")
+		if err != nil {
+			t.Fatalf("GetLightByName: %v", err)
+		}
+		if light.ID != "1" {
+			t.Fatalf("GetLightByName returned light %s, want 1", light.ID)
+		}
+	}
+	if n := atomic.LoadInt32(&getLights); n != 1 {
+		t.Errorf("two lookups within CacheTTL sent %d GET /lights, want 1", n)
+	}
+}
+
+func TestCacheToggle(t *testing.T) {
+	var getLights int32
+	srv, b := newCachingBridge(t, &getLights)
+
+	light, err := b.GetLightByName("Lamp")
+	if err != nil {
+		t.Fatalf("GetLightByName: %v", err)
+	}
+	err = light.Toggle()
+	if err != nil {
+		t.Fatalf("Toggle: %v", err)
+	}
+	if n := atomic.LoadInt32(&getLights); n != 1 {
+		t.Errorf("Toggle after a lookup sent %d GET /lights in total, want 1", n)
+	}
+	if attributes, _ := srv.Light("1"); !attributes.State.On {
+		t.Errorf("Toggle did not turn the light on, using the cached off state")
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	var getLights int32
+	_, b := newCachingBridge(t, &getLights)
+
+	_, err := b.GetAllLights()
+	if err != nil {
+		t.Fatalf("GetAllLights: %v", err)
+	}
+	b.InvalidateCache()
+	_, err = b.GetAllLights()
+	if err != nil {
+		t.Fatalf("GetAllLights: %v", err)
+	}
+	if n := atomic.LoadInt32(&getLights); n != 2 {
+		t.Errorf("GetAllLights after InvalidateCache sent %d GET /lights in total, want 2", n)
+	}
+}
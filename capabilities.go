@@ -0,0 +1,67 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrCapabilitiesNotSupported is returned by FetchCapabilities when the bridge does not support the capabilities endpoint.
+var ErrCapabilitiesNotSupported = errors.New("bridge does not support capabilities, a newer bridge software version is required")
+
+// ResourceCapability holds the number of resources of a kind that can still be created, and the total supported.
+type ResourceCapability struct {
+	Available int `json:"available"` // number of resources that can still be created
+	Total     int `json:"total"`     // total number of resources supported by the bridge
+}
+
+// Capabilities holds the resource limits of a bridge.
+type Capabilities struct {
+	Lights  ResourceCapability `json:"lights"`
+	Sensors struct {
+		ResourceCapability
+		CLIP ResourceCapability `json:"clip"` // virtual sensors
+		ZLL  ResourceCapability `json:"zll"`  // ZigBee Light Link sensors
+		ZGP  ResourceCapability `json:"zgp"`  // ZigBee Green Power sensors
+	} `json:"sensors"`
+	Groups ResourceCapability `json:"groups"`
+	Scenes struct {
+		ResourceCapability
+		LightStates ResourceCapability `json:"lightstates"` // light states stored over all scenes
+	} `json:"scenes"`
+	Schedules ResourceCapability `json:"schedules"`
+	Rules     struct {
+		ResourceCapability
+		Conditions ResourceCapability `json:"conditions"` // conditions over all rules
+		Actions    ResourceCapability `json:"actions"`    // actions over all rules
+	} `json:"rules"`
+	ResourceLinks ResourceCapability `json:"resourcelinks"`
+	Streaming     struct {
+		ResourceCapability
+		Channels int `json:"channels"` // number of channels per entertainment stream
+	} `json:"streaming"`
+}
+
+// FetchCapabilities fetches the resource limits of the bridge.
+// ErrCapabilitiesNotSupported is returned for bridges that are too old to support this.
+func (b *Bridge) FetchCapabilities() (*Capabilities, error) {
+	return b.FetchCapabilitiesContext(context.Background())
+}
+
+// FetchCapabilitiesContext is like FetchCapabilities, the request is bound to the given context.
+func (b *Bridge) FetchCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	capabilities := &Capabilities{}
+	err := b.getJSON(ctx, b.URL()+"/capabilities", capabilities)
+	if err != nil {
+		// older bridge software responds with 404 or reports the unknown resource as error
+		var transportError *TransportError
+		if errors.As(err, &transportError) && transportError.StatusCode == http.StatusNotFound {
+			return nil, ErrCapabilitiesNotSupported
+		}
+		if isAPIErrorType(err, ErrorTypeResourceNotAvailable) || isAPIErrorType(err, ErrorTypeMethodNotAvailable) {
+			return nil, ErrCapabilitiesNotSupported
+		}
+		return nil, err
+	}
+	return capabilities, nil
+}
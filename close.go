@@ -0,0 +1,69 @@
+package hue
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrBridgeClosed is returned by Subscribe and Track after the bridge was closed.
+var ErrBridgeClosed = errors.New("bridge is closed")
+
+// Close releases the long-lived resources of the bridge: the event streams opened by Subscribe are closed,
+// which closes their channels, and the DTLS connections opened by package entertainment are closed.
+// Close returns once the goroutines of the event streams have ended. It is safe to call Close multiple times,
+// subsequent calls do nothing. Regular requests keep working after Close, but no new streams can be opened.
+// The first error encountered while closing is returned.
+func (b *Bridge) Close() error {
+	b.closeMu.Lock()
+	if b.closed {
+		b.closeMu.Unlock()
+		return nil
+	}
+	b.closed = true
+	closers := b.closers
+	b.closers = nil
+	b.closeMu.Unlock()
+
+	keys := make([]int, 0, len(closers))
+	for key := range closers {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	var err error
+	for _, key := range keys {
+		if errClose := closers[key](); err == nil {
+			err = errClose
+		}
+	}
+	b.running.Wait()
+	return err
+}
+
+// Track registers a long-lived resource, like a stream of package entertainment, release is called by Close to release it.
+// The returned untrack function must be called once the resource is released, Close waits for it.
+// ErrBridgeClosed is returned when the bridge was already closed.
+func (b *Bridge) Track(release func() error) (untrack func(), err error) {
+	b.closeMu.Lock()
+	defer b.closeMu.Unlock()
+	if b.closed {
+		return nil, ErrBridgeClosed
+	}
+	if b.closers == nil {
+		b.closers = make(map[int]func() error)
+	}
+	key := b.nextCloser
+	b.nextCloser++
+	b.closers[key] = release
+	b.running.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.closeMu.Lock()
+			delete(b.closers, key)
+			b.closeMu.Unlock()
+			b.running.Done()
+		})
+	}, nil
+}
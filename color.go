@@ -0,0 +1,272 @@
+package hue
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// RGBToXY converts a RGB color to the x and y coordinates in the CIE 1931 color space, as used by the hue lights.
+// Gamma correction is applied to the color components before they are converted using the Wide RGB D65 conversion.
+// The resulting coordinates are clamped to the range 0..1. Black has no chromaticity and results in 0,0.
+// They are not limited to the gamut of a light, e.g. pure red results in about 0.7006,0.2993; see ClosestInGamut.
+func RGBToXY(r, g, b uint8) (float64, float64) {
+	red := gammaCorrect(float64(r) / 255)
+	green := gammaCorrect(float64(g) / 255)
+	blue := gammaCorrect(float64(b) / 255)
+
+	X := red*0.664511 + green*0.154324 + blue*0.162028
+	Y := red*0.283881 + green*0.668433 + blue*0.047685
+	Z := red*0.000088 + green*0.072310 + blue*0.986039
+
+	sum := X + Y + Z
+	if sum == 0 {
+		return 0, 0
+	}
+	return clampUnit(X / sum), clampUnit(Y / sum)
+}
+
+// XYToRGB converts x and y coordinates in the CIE 1931 color space with given brightness (1..254) to a RGB color.
+// It is the inverse of RGBToXY. Colors that would be brighter than representable in RGB are scaled down.
+func XYToRGB(x, y float64, bri uint8) (r, g, b uint8) {
+	if y == 0 {
+		return 0, 0, 0
+	}
+
+	Y := math.Min(float64(bri)/254, 1)
+	X := (Y / y) * x
+	Z := (Y / y) * (1 - x - y)
+
+	red := X*1.656492 - Y*0.354851 - Z*0.255038
+	green := -X*0.707196 + Y*1.655397 + Z*0.036152
+	blue := X*0.051713 - Y*0.121364 + Z*1.011530
+
+	// scale down when a component exceeds the representable range
+	if largest := math.Max(red, math.Max(green, blue)); largest > 1 {
+		red, green, blue = red/largest, green/largest, blue/largest
+	}
+
+	return toRGBComponent(red), toRGBComponent(green), toRGBComponent(blue)
+}
+
+// XY holds the x and y coordinates of a color in CIE color space, in that order.
+// It is encoded as json array [x,y], the form used by light states, group actions and scenes.
+type XY [2]float64
+
+// X returns the x coordinate.
+func (xy XY) X() float64 {
+	return xy[0]
+}
+
+// Y returns the y coordinate.
+func (xy XY) Y() float64 {
+	return xy[1]
+}
+
+// RGB converts the coordinates with given brightness (1..254) to a RGB color, see XYToRGB.
+func (xy XY) RGB(bri uint8) (uint8, uint8, uint8) {
+	return XYToRGB(xy[0], xy[1], bri)
+}
+
+// RGB returns the color of the light state as RGB color, converted from the xy coordinates and brightness.
+func (s *LightState) RGB() (uint8, uint8, uint8) {
+	return s.XY.RGB(s.Brightness)
+}
+
+// MiredToRGB approximates the RGB color of a white light with the given color temperature in mired.
+// The temperature is converted to Kelvin and then to RGB with Tanner Helland's approximation.
+func MiredToRGB(mired uint16) (uint8, uint8, uint8) {
+	if mired == 0 {
+		mired = 1
+	}
+	temp := 1000000 / float64(mired) / 100
+
+	var red, green, blue float64
+	if temp <= 66 {
+		red = 255
+		green = 99.4708025861*math.Log(temp) - 161.1195681661
+	} else {
+		red = 329.698727446 * math.Pow(temp-60, -0.1332047592)
+		green = 288.1221695283 * math.Pow(temp-60, -0.0755148492)
+	}
+	switch {
+	case temp >= 66:
+		blue = 255
+	case temp <= 19:
+		blue = 0
+	default:
+		blue = 138.5177312231*math.Log(temp-10) - 305.0447927307
+	}
+
+	return clampRGBComponent(red), clampRGBComponent(green), clampRGBComponent(blue)
+}
+
+// ApproxRGB returns an approximation of the color of the light state as RGB color.
+// Depending on the ColorMode, it is converted from the color temperature, the hue and saturation, or the xy coordinates.
+func (s *LightState) ApproxRGB() (uint8, uint8, uint8) {
+	switch s.ColorMode {
+	case ColorModeCT:
+		r, g, b := MiredToRGB(s.CT)
+		scale := math.Min(float64(s.Brightness)/254, 1)
+		return uint8(math.Round(float64(r) * scale)), uint8(math.Round(float64(g) * scale)), uint8(math.Round(float64(b) * scale))
+	case ColorModeHS:
+		return hsvToRGB(float64(s.Hue)/math.MaxUint16*360, math.Min(float64(s.Saturation)/254, 1), math.Min(float64(s.Brightness)/254, 1))
+	default:
+		return s.RGB()
+	}
+}
+
+// hsvToRGB converts a color with hue in degrees, and saturation and value in the range 0..1, to RGB.
+func hsvToRGB(h, sat, v float64) (uint8, uint8, uint8) {
+	c := v * sat
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+
+	var red, green, blue float64
+	switch {
+	case h < 60:
+		red, green, blue = c, x, 0
+	case h < 120:
+		red, green, blue = x, c, 0
+	case h < 180:
+		red, green, blue = 0, c, x
+	case h < 240:
+		red, green, blue = 0, x, c
+	case h < 300:
+		red, green, blue = x, 0, c
+	default:
+		red, green, blue = c, 0, x
+	}
+	return clampRGBComponent((red + m) * 255), clampRGBComponent((green + m) * 255), clampRGBComponent((blue + m) * 255)
+}
+
+// clampRGBComponent rounds and clamps v to the range 0..255.
+func clampRGBComponent(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+// toRGBComponent applies reverse gamma correction to a linear color component in the range 0..1 and scales it to 0..255.
+func toRGBComponent(v float64) uint8 {
+	v = clampUnit(v)
+	if v <= 0.0031308 {
+		v = 12.92 * v
+	} else {
+		v = (1.0+0.055)*math.Pow(v, 1.0/2.4) - 0.055
+	}
+	return uint8(math.Round(clampUnit(v) * 255))
+}
+
+// gammaCorrect applies the sRGB gamma correction to a color component in the range 0..1.
+func gammaCorrect(v float64) float64 {
+	if v > 0.04045 {
+		return math.Pow((v+0.055)/(1.0+0.055), 2.4)
+	}
+	return v / 12.92
+}
+
+// clampUnit clamps v to the range 0..1.
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// SetColor sets the color of the light to the given x and y coordinates in CIE color space.
+// Both coordinates are clamped to the range 0..1. When the gamut of the light's model is known,
+// coordinates outside of it are moved to the closest color the light can reproduce, see ClosestInGamut.
+func (l *Light) SetColor(x, y float64) error {
+	return l.SetColorContext(context.Background(), x, y)
+}
+
+// SetColorContext is like SetColor, the request is bound to the given context.
+func (l *Light) SetColorContext(ctx context.Context, x, y float64) error {
+	x, y = clampUnit(x), clampUnit(y)
+	if g, ok := GamutForModel(l.ModelID); ok {
+		x, y = ClosestInGamut(x, y, g)
+	}
+	xy := XY{x, y}
+	return l.SetStateContext(ctx, LightStateChange{XY: &xy})
+}
+
+// Colors holds named color presets as x and y coordinates in CIE color space, for use with SetNamedColor.
+// All presets lie within the color gamuts A, B and C, so they render alike on all color lights.
+var Colors = map[string]XY{
+	"red":       {0.6650, 0.3190},
+	"orange":    {0.5600, 0.4000},
+	"yellow":    {0.4350, 0.4900},
+	"green":     {0.4083, 0.5162},
+	"blue":      {0.2050, 0.1100},
+	"purple":    {0.2400, 0.1250},
+	"pink":      {0.3944, 0.3093},
+	"white":     {0.3227, 0.3290},
+	"warmwhite": {0.4596, 0.4105},
+	"coolwhite": {0.3131, 0.3232},
+}
+
+// SetNamedColor sets the color of the light to one of the presets in Colors, e.g. “red” or “warmwhite”.
+// Names are matched case-insensitively. An error listing the available presets is returned for unknown names.
+func (l *Light) SetNamedColor(name string) error {
+	return l.SetNamedColorContext(context.Background(), name)
+}
+
+// SetNamedColorContext is like SetNamedColor, the request is bound to the given context.
+func (l *Light) SetNamedColorContext(ctx context.Context, name string) error {
+	xy, ok := Colors[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(Colors))
+		for colorName := range Colors {
+			names = append(names, colorName)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown color %q, must be one of: %s", name, strings.Join(names, ", "))
+	}
+	return l.SetColorContext(ctx, xy[0], xy[1])
+}
+
+// SetRGB sets the color of the light to the given RGB color. The color is converted using RGBToXY,
+// and clamped to the gamut of the light like SetColor does.
+func (l *Light) SetRGB(r, g, b uint8) error {
+	return l.SetRGBContext(context.Background(), r, g, b)
+}
+
+// SetRGBContext is like SetRGB, the request is bound to the given context.
+func (l *Light) SetRGBContext(ctx context.Context, r, g, b uint8) error {
+	x, y := RGBToXY(r, g, b)
+	return l.SetColorContext(ctx, x, y)
+}
+
+// SetHueSaturation sets the hue and saturation of the light.
+// Hue is a wrapping value between 0 and 65535, both 0 and 65535 are red.
+// Saturation ranges from 0 (white) to 254 (most saturated), larger values are clamped to 254.
+func (l *Light) SetHueSaturation(hue uint16, sat uint8) error {
+	return l.SetHueSaturationContext(context.Background(), hue, sat)
+}
+
+// SetHueSaturationContext is like SetHueSaturation, the request is bound to the given context.
+func (l *Light) SetHueSaturationContext(ctx context.Context, hue uint16, sat uint8) error {
+	if sat > 254 {
+		sat = 254
+	}
+	return l.SetStateContext(ctx, LightStateChange{Hue: &hue, Sat: &sat})
+}
+
+// SetHueDegrees sets the hue of the light from an angle on the color wheel in degrees, see DegreesToHue.
+func (l *Light) SetHueDegrees(deg float64) error {
+	return l.SetHueDegreesContext(context.Background(), deg)
+}
+
+// SetHueDegreesContext is like SetHueDegrees, the request is bound to the given context.
+func (l *Light) SetHueDegreesContext(ctx context.Context, deg float64) error {
+	hue := DegreesToHue(deg)
+	return l.SetStateContext(ctx, LightStateChange{Hue: &hue})
+}
+
+// DegreesToHue converts an angle on the color wheel in degrees to the hue scale of 0..65535 used by the lights.
+// Angles outside 0..360 wrap around, e.g. 0 is red, 120 is green and 240 is blue.
+func DegreesToHue(deg float64) uint16 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return uint16(math.Round(deg / 360 * math.MaxUint16))
+}
@@ -0,0 +1,104 @@
+package hue_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/GeertJohan/go.hue"
+)
+
+func TestRGBToXY(t *testing.T) {
+	// the primaries of the Wide RGB D65 conversion
+	tests := []struct {
+		r, g, b uint8
+		x, y    float64
+	}{
+		{255, 0, 0, 0.7006, 0.2993},
+		{0, 255, 0, 0.1724, 0.7468},
+		{0, 0, 255, 0.1355, 0.0399},
+	}
+	for _, test := range tests {
+		x, y := hue.RGBToXY(test.r, test.g, test.b)
+		if math.Abs(x-test.x) > 0.0005 || math.Abs(y-test.y) > 0.0005 {
+			t.Errorf("RGBToXY(%d, %d, %d) = %.4f, %.4f, want %.4f, %.4f", test.r, test.g, test.b, x, y, test.x, test.y)
+		}
+	}
+
+	for _, color := range [][3]uint8{{0, 0, 0}, {255, 255, 255}, {255, 0, 0}, {0, 255, 0}, {0, 0, 255}, {1, 0, 0}, {255, 255, 0}, {0, 255, 255}, {128, 64, 200}} {
+		x, y := hue.RGBToXY(color[0], color[1], color[2])
+		if x < 0 || x > 1 || y < 0 || y > 1 {
+			t.Errorf("RGBToXY(%v) = %v, %v, want both within [0,1]", color, x, y)
+		}
+	}
+}
+
+func TestClosestInGamutPureRed(t *testing.T) {
+	// pure red lies outside of gamut B and is moved to its red corner
+	x, y := hue.ClosestInGamut(0.7006, 0.2993, hue.GamutB)
+	if math.Abs(x-0.675) > 0.0005 || math.Abs(y-0.322) > 0.0005 {
+		t.Errorf("ClosestInGamut(0.7006, 0.2993, GamutB) = %.4f, %.4f, want 0.6750, 0.3220", x, y)
+	}
+
+	// coordinates within the gamut are returned unchanged
+	if x, y := hue.ClosestInGamut(0.4, 0.3, hue.GamutB); x != 0.4 || y != 0.3 {
+		t.Errorf("ClosestInGamut(0.4, 0.3, GamutB) = %v, %v, want 0.4, 0.3", x, y)
+	}
+}
+
+func TestDegreesToHue(t *testing.T) {
+	tests := []struct {
+		deg float64
+		hue uint16
+	}{
+		{0, 0},
+		{120, 21845},
+		{240, 43690},
+		{360, 0},
+		{-120, 43690},
+	}
+	for _, test := range tests {
+		if hue := hue.DegreesToHue(test.deg); hue != test.hue {
+			t.Errorf("DegreesToHue(%v) = %d, want %d", test.deg, hue, test.hue)
+		}
+	}
+}
+
+func TestSetHueDegrees(t *testing.T) {
+	for deg, want := range map[float64]uint16{0: 0, 120: 21845, 240: 43690} {
+		srv := newLampServer()
+		defer srv.Close()
+		err := lamp(t, srv).SetHueDegrees(deg)
+		if err != nil {
+			t.Fatalf("SetHueDegrees(%v): %v", deg, err)
+		}
+		commands := srv.CommandsTo("/lights/1/state")
+		if len(commands) != 1 || string(commands[0].Body) != fmt.Sprintf(`{"hue":%d}`, want) {
+			t.Errorf("SetHueDegrees(%v) sent %+v, want a single body {\"hue\":%d}", deg, commands, want)
+		}
+	}
+}
+
+func TestRGBToXYRoundTrip(t *testing.T) {
+	const tolerance = 2
+	colors := [][3]uint8{
+		{255, 0, 0},
+		{0, 255, 0},
+		{0, 0, 255},
+	}
+	for _, color := range colors {
+		x, y := hue.RGBToXY(color[0], color[1], color[2])
+		r, g, b := hue.XYToRGB(x, y, 254)
+		got := [3]uint8{r, g, b}
+		for i := range color {
+			if diff := int(got[i]) - int(color[i]); diff < -tolerance || diff > tolerance {
+				t.Errorf("XYToRGB(RGBToXY(%v)) = %v, want within %d of %v", color, got, tolerance, color)
+				break
+			}
+		}
+		state := hue.LightState{XY: [2]float64{x, y}, Brightness: 254}
+		if r, g, b := state.RGB(); [3]uint8{r, g, b} != got {
+			t.Errorf("LightState.RGB() = %v, want %v like XYToRGB", [3]uint8{r, g, b}, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package hue
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Datastore holds all resources of the bridge, as retrieved at once by FetchDatastore.
+// All slices are sorted by their numeric ID.
+type Datastore struct {
+	Config    BridgeConfiguration // configuration of the bridge
+	Lights    []*Light            // all lights, including their name and state
+	Groups    []*Group            // all groups, without the special group with AllLightsGroupID
+	Scenes    []*Scene            // all scenes
+	Schedules []*Schedule         // all schedules
+	Sensors   []*Sensor           // all sensors
+	Rules     []*Rule             // all rules
+}
+
+// FetchDatastore retrieves all resources of the bridge with a single request.
+// This is much more efficient than fetching them one type at a time, e.g. to take a snapshot at startup.
+func (b *Bridge) FetchDatastore() (*Datastore, error) {
+	return b.FetchDatastoreContext(context.Background())
+}
+
+// FetchDatastoreContext is like FetchDatastore, the request is bound to the given context.
+func (b *Bridge) FetchDatastoreContext(ctx context.Context) (*Datastore, error) {
+	full := struct {
+		Config    BridgeConfiguration         `json:"config"`
+		Lights    map[string]*LightAttributes `json:"lights"`
+		Groups    map[string]*Group           `json:"groups"`
+		Scenes    map[string]*Scene           `json:"scenes"`
+		Schedules map[string]*Schedule        `json:"schedules"`
+		Sensors   map[string]*Sensor          `json:"sensors"`
+		Rules     map[string]*Rule            `json:"rules"`
+	}{}
+	err := b.getJSON(ctx, b.URL(), &full)
+	if err != nil {
+		return nil, err
+	}
+	fetched := time.Now()
+	if full.Lights == nil {
+		full.Lights = map[string]*LightAttributes{}
+	}
+	b.cacheLights(full.Lights, fetched)
+
+	datastore := &Datastore{
+		Config:    full.Config,
+		Lights:    b.newLights(full.Lights, fetched),
+		Groups:    make([]*Group, 0, len(full.Groups)),
+		Scenes:    make([]*Scene, 0, len(full.Scenes)),
+		Schedules: make([]*Schedule, 0, len(full.Schedules)),
+		Sensors:   make([]*Sensor, 0, len(full.Sensors)),
+		Rules:     make([]*Rule, 0, len(full.Rules)),
+	}
+	for groupID, group := range full.Groups {
+		group.bridge = b
+		group.ID = groupID
+		datastore.Groups = append(datastore.Groups, group)
+	}
+	for sceneID, scene := range full.Scenes {
+		scene.ID = sceneID
+		datastore.Scenes = append(datastore.Scenes, scene)
+	}
+	for scheduleID, schedule := range full.Schedules {
+		schedule.ID = scheduleID
+		datastore.Schedules = append(datastore.Schedules, schedule)
+	}
+	for sensorID, sensor := range full.Sensors {
+		sensor.ID = sensorID
+		datastore.Sensors = append(datastore.Sensors, sensor)
+	}
+	for ruleID, rule := range full.Rules {
+		rule.ID = ruleID
+		datastore.Rules = append(datastore.Rules, rule)
+	}
+
+	sort.Slice(datastore.Groups, func(i, j int) bool {
+		return lessNumericID(datastore.Groups[i].ID, datastore.Groups[j].ID)
+	})
+	sort.Slice(datastore.Scenes, func(i, j int) bool {
+		return lessNumericID(datastore.Scenes[i].ID, datastore.Scenes[j].ID)
+	})
+	sort.Slice(datastore.Schedules, func(i, j int) bool {
+		return lessNumericID(datastore.Schedules[i].ID, datastore.Schedules[j].ID)
+	})
+	sort.Slice(datastore.Sensors, func(i, j int) bool {
+		return lessNumericID(datastore.Sensors[i].ID, datastore.Sensors[j].ID)
+	})
+	sort.Slice(datastore.Rules, func(i, j int) bool {
+		return lessNumericID(datastore.Rules[i].ID, datastore.Rules[j].ID)
+	})
+	return datastore, nil
+}
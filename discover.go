@@ -1,8 +1,18 @@
 package hue
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 // BrokerDetails represents the details of a single bridge as returned by the broker service
@@ -12,17 +22,47 @@ type BrokerDetails struct {
 	MACAddress        string `json:"macaddress"`
 }
 
-// DiscoverBridges requests a list of known bridges from the Philips meethue broker service.
-// It returns a slice of BrokerBridgeDetails or a non-nil error
-// The list of BrokerBridgeDetails can have len 0 while error is nil.
-// This means the request was successfull, but the broker did not return any details.
-func DiscoverBridges() ([]BrokerDetails, error) {
-	// request data from Philips' meethue broker service
-	brokerResponse, err := http.Get("https://www.meethue.com/api/nupnp")
+// Errors wrapped by the error returned from DiscoverBridges, to tell why discovery failed.
+var (
+	ErrDiscoveryTimeout   = errors.New("discovery service did not respond in time")
+	ErrDiscoveryMalformed = errors.New("discovery service returned a malformed response")
+)
+
+// discoveryTimeout is the time DiscoverBridges waits for the discovery service
+const discoveryTimeout = 10 * time.Second
+
+// discoveryURL is the url of the Philips meethue discovery service
+const discoveryURL = "https://discovery.meethue.com"
+
+// DiscoverBridges requests a list of known bridges from the Philips meethue discovery service (N-UPnP).
+// It returns a Bridge for each discovered bridge with the IP and BridgeID fields set, or a non-nil error.
+// The list of bridges can have len 0 while error is nil.
+// This means the request was successfull, but the discovery service did not return any bridges.
+// DiscoverBridges waits at most 10 seconds for the discovery service, use DiscoverBridgesContext to choose the timeout.
+func DiscoverBridges() ([]*Bridge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+	defer cancel()
+	return DiscoverBridgesContext(ctx)
+}
+
+// DiscoverBridgesContext is like DiscoverBridges, the request is bound to the given context.
+// When the context expires before the discovery service responded, the error wraps ErrDiscoveryTimeout.
+// When the response can't be decoded, the error wraps ErrDiscoveryMalformed.
+func DiscoverBridgesContext(ctx context.Context) ([]*Bridge, error) {
+	// request data from Philips' meethue discovery service
+	request, err := http.NewRequestWithContext(ctx, "GET", discoveryURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	brokerResponse, err := discoveryHTTPClient.Do(request)
+	if err != nil {
+		return nil, discoveryError(ctx, err)
+	}
 	defer brokerResponse.Body.Close()
+	err = checkResponse(brokerResponse)
+	if err != nil {
+		return nil, err
+	}
 
 	// create brokerBridgeDetails slice
 	bd := make([]BrokerDetails, 0)
@@ -30,9 +70,114 @@ func DiscoverBridges() ([]BrokerDetails, error) {
 	// deocde response body into BrokerBridgeDetails slice
 	err = json.NewDecoder(brokerResponse.Body).Decode(&bd)
 	if err != nil {
-		return nil, err
+		var syntaxError *json.SyntaxError
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &syntaxError) || errors.As(err, &typeError) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: %v", ErrDiscoveryMalformed, err)
+		}
+		return nil, discoveryError(ctx, err)
+	}
+
+	// create a bridge for each of the details
+	bridges := make([]*Bridge, 0, len(bd))
+	for _, details := range bd {
+		bridge := NewBridge(details.InternalIPAddress)
+		bridge.BridgeID = details.ID
+		bridges = append(bridges, bridge)
 	}
 
 	// all done
-	return bd, nil
+	return bridges, nil
+}
+
+// discoveryHTTPClient is used for requests to the discovery service, the timeout is set with the context.
+var discoveryHTTPClient = &http.Client{}
+
+// discoveryError wraps ErrDiscoveryTimeout around err when it was caused by a timeout.
+func discoveryError(ctx context.Context, err error) error {
+	var netError net.Error
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || (errors.As(err, &netError) && netError.Timeout()) {
+		return fmt.Errorf("%w: %v", ErrDiscoveryTimeout, err)
+	}
+	return err
+}
+
+// ssdpAddress is the multicast address on which SSDP M-SEARCH requests are sent
+const ssdpAddress = "239.255.255.250:1900"
+
+// ssdpSearchRequest is the M-SEARCH request that is sent to discover bridges
+var ssdpSearchRequest = "M-SEARCH * HTTP/1.1\r\n" +
+	"HOST: " + ssdpAddress + "\r\n" +
+	"MAN: \"ssdp:discover\"\r\n" +
+	"MX: 3\r\n" +
+	"ST: ssdp:basic\r\n" +
+	"\r\n"
+
+// DiscoverBridgesSSDP discovers bridges on the local network using SSDP (UPnP).
+// It sends an M-SEARCH request and collects responses from bridges until the given timeout has passed.
+// Unlike DiscoverBridges, this does not require internet access.
+// Bridges that answer multiple times are returned only once.
+func DiscoverBridgesSSDP(timeout time.Duration) ([]*Bridge, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	addr, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.WriteTo([]byte(ssdpSearchRequest), addr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = conn.SetReadDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	bridges := make([]*Bridge, 0)
+	seen := make(map[string]bool)
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				// timeout reached, all done
+				return bridges, nil
+			}
+			return nil, err
+		}
+
+		bridge := parseSSDPResponse(buf[:n])
+		if bridge == nil || seen[bridge.IP] {
+			continue
+		}
+		seen[bridge.IP] = true
+		bridges = append(bridges, bridge)
+	}
+}
+
+// parseSSDPResponse parses a single SSDP response.
+// It returns a Bridge when the response was sent by a hue bridge, or nil otherwise.
+func parseSSDPResponse(data []byte) *Bridge {
+	response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return nil
+	}
+	response.Body.Close()
+
+	if !strings.Contains(response.Header.Get("Server"), "IpBridge") {
+		return nil
+	}
+	location, err := url.Parse(response.Header.Get("Location"))
+	if err != nil || location.Hostname() == "" {
+		return nil
+	}
+
+	bridge := NewBridge(location.Hostname())
+	bridge.BridgeID = response.Header.Get("Hue-Bridgeid")
+	return bridge
 }
@@ -0,0 +1,74 @@
+package hue
+
+import (
+	"context"
+	"errors"
+)
+
+// EntertainmentPort is the UDP port on which the bridge accepts DTLS connections for streaming to an entertainment area.
+const EntertainmentPort = 2100
+
+// GroupStream holds the streaming state of an entertainment area.
+type GroupStream struct {
+	Active    bool   `json:"active"`    // whether streaming is active
+	Owner     string `json:"owner"`     // username of the application that activated streaming, empty when not active
+	ProxyMode string `json:"proxymode"` // how the proxy node is chosen, “auto” or “manual”
+	ProxyNode string `json:"proxynode"` // path of the light that relays the stream to the other lights
+}
+
+// CreateEntertainmentArea creates a new entertainment area with the given name and class, containing the given lights.
+// Class must be one of GroupClasses, when empty “Other” is used. Only lights that support streaming can be added.
+func (b *Bridge) CreateEntertainmentArea(name string, class string, lightIDs []string) (*Group, error) {
+	return b.CreateEntertainmentAreaContext(context.Background(), name, class, lightIDs)
+}
+
+// CreateEntertainmentAreaContext is like CreateEntertainmentArea, the request is bound to the given context.
+func (b *Bridge) CreateEntertainmentAreaContext(ctx context.Context, name string, class string, lightIDs []string) (*Group, error) {
+	if len(lightIDs) == 0 {
+		return nil, errors.New("an entertainment area must contain at least one light")
+	}
+	return b.createClassifiedGroup(ctx, GroupTypeEntertainment, name, class, lightIDs)
+}
+
+// StartStreaming activates streaming for the entertainment area, with the bridge's Username as owner.
+// Once active, the bridge accepts a DTLS connection on EntertainmentPort, see package entertainment. The resulting stream state is returned.
+func (g *Group) StartStreaming() (*GroupStream, error) {
+	return g.StartStreamingContext(context.Background())
+}
+
+// StartStreamingContext is like StartStreaming, the requests are bound to the given context.
+func (g *Group) StartStreamingContext(ctx context.Context) (*GroupStream, error) {
+	return g.setStreaming(ctx, true)
+}
+
+// StopStreaming deactivates streaming for the entertainment area. The resulting stream state is returned.
+func (g *Group) StopStreaming() (*GroupStream, error) {
+	return g.StopStreamingContext(context.Background())
+}
+
+// StopStreamingContext is like StopStreaming, the requests are bound to the given context.
+func (g *Group) StopStreamingContext(ctx context.Context) (*GroupStream, error) {
+	return g.setStreaming(ctx, false)
+}
+
+// setStreaming sets the active flag of the stream, and fetches the stream state from the bridge to update the group.
+func (g *Group) setStreaming(ctx context.Context, active bool) (*GroupStream, error) {
+	if g.Type != "" && g.Type != GroupTypeEntertainment {
+		return nil, errors.New("streaming is only available for entertainment areas")
+	}
+	change := map[string]interface{}{"stream": map[string]bool{"active": active}}
+	_, err := g.bridge.sendAPIRequest(ctx, "PUT", g.bridge.URL()+"/groups/"+g.ID, change)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := g.bridge.getGroup(ctx, g.ID)
+	if err != nil {
+		return nil, err
+	}
+	if group.Stream == nil {
+		return nil, errors.New("bridge did not report a stream state for group " + g.ID)
+	}
+	g.Stream = group.Stream
+	return g.Stream, nil
+}
@@ -0,0 +1,143 @@
+// Package entertainment streams colors to the lights of an entertainment area over DTLS, using the HueStream protocol.
+// It is kept apart from package hue so that only programs that stream depend on a DTLS implementation.
+// Streaming must have been activated with Group.StartStreaming of package hue before a stream is opened.
+package entertainment
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GeertJohan/go.hue"
+	"github.com/pion/dtls/v2"
+)
+
+// FrameInterval is the minimum interval between frames sent by Flush, limiting the stream to 50 frames per second.
+const FrameInterval = 20 * time.Millisecond
+
+// Stream is a DTLS connection to the bridge, streaming colors to the lights of an entertainment area.
+// Colors are collected with SetLightColor and sent together as a single frame by Flush.
+type Stream struct {
+	conn    net.Conn
+	untrack func() // unregisters the stream from Bridge.Close, guarded by mu
+
+	closeOnce sync.Once
+	closeErr  error
+
+	mu        sync.Mutex
+	colors    map[uint8][3]uint16 // colors to send, by light id
+	lastFlush time.Time
+}
+
+// Open opens a DTLS connection to the bridge for streaming to the entertainment area on hue.EntertainmentPort.
+// The Username of the bridge is used as PSK identity, clientKey is the hex encoded client key that was returned
+// when the user was created, when empty the ClientKey of the bridge is used.
+// The caller should call Close when finished, to close the connection, Bridge.Close closes it as well.
+func Open(ctx context.Context, bridge *hue.Bridge, clientKey string) (*Stream, error) {
+	ip, username, bridgeClientKey := bridge.Credentials()
+	if len(clientKey) == 0 {
+		clientKey = bridgeClientKey
+	}
+	if len(clientKey) == 0 {
+		return nil, errors.New("a client key is required for streaming, see CreateNewUserWithClientKey")
+	}
+	psk, err := hex.DecodeString(clientKey)
+	if err != nil {
+		return nil, errors.New("client key must be hex encoded: " + err.Error())
+	}
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(ip, strconv.Itoa(hue.EntertainmentPort)))
+	if err != nil {
+		return nil, err
+	}
+	config := &dtls.Config{
+		PSK: func([]byte) ([]byte, error) {
+			return psk, nil
+		},
+		PSKIdentityHint: []byte(username),
+		CipherSuites:    []dtls.CipherSuiteID{dtls.TLS_PSK_WITH_AES_128_GCM_SHA256},
+	}
+	conn, err := dtls.DialWithContext(ctx, "udp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+	stream := newStream(conn)
+	// Bridge.Close may close the stream right away, mu makes it wait until untrack is set
+	stream.mu.Lock()
+	stream.untrack, err = bridge.Track(stream.Close)
+	stream.mu.Unlock()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return stream, nil
+}
+
+// newStream returns a stream sending its frames on conn.
+func newStream(conn net.Conn) *Stream {
+	return &Stream{
+		conn:   conn,
+		colors: make(map[uint8][3]uint16),
+	}
+}
+
+// SetLightColor sets the RGB color for the light with given id (channel) in the entertainment area,
+// with 16 bits per component. The color is sent with the next Flush.
+func (s *Stream) SetLightColor(channel uint8, r, g, b uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.colors[channel] = [3]uint16{r, g, b}
+}
+
+// Flush sends the colors of all lights set so far as a single frame in the HueStream protocol.
+// When the previous frame was sent less than FrameInterval ago, Flush waits before sending.
+// The bridge ends streaming when it receives no frames for 10 seconds, so frames should be sent continuously.
+func (s *Stream) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if wait := FrameInterval - time.Since(s.lastFlush); wait > 0 {
+		time.Sleep(wait)
+	}
+	_, err := s.conn.Write(s.frame())
+	s.lastFlush = time.Now()
+	return err
+}
+
+// frame builds a HueStream version 1 packet holding the colors, in RGB color space.
+func (s *Stream) frame() []byte {
+	packet := make([]byte, 0, 16+9*len(s.colors))
+	packet = append(packet, "HueStream"...)
+	packet = append(packet,
+		0x01, 0x00, // protocol version 1.0
+		0x00,       // sequence id, ignored by the bridge
+		0x00, 0x00, // reserved
+		0x00, // color space RGB
+		0x00, // reserved
+	)
+	for channel, color := range s.colors {
+		packet = append(packet, 0x00) // device type light
+		packet = binary.BigEndian.AppendUint16(packet, uint16(channel))
+		for _, component := range color {
+			packet = binary.BigEndian.AppendUint16(packet, component)
+		}
+	}
+	return packet
+}
+
+// Close closes the DTLS connection. Streaming remains active on the bridge until StopStreaming is called.
+// It is safe to call Close multiple times, subsequent calls return the result of the first.
+func (s *Stream) Close() error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		untrack := s.untrack
+		s.mu.Unlock()
+		s.closeErr = s.conn.Close()
+		untrack()
+	})
+	return s.closeErr
+}
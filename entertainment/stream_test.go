@@ -0,0 +1,17 @@
+package entertainment
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFrame(t *testing.T) {
+	s := newStream(nil)
+	s.SetLightColor(3, 0xffff, 0x0102, 0)
+	want := []byte("HueStream")
+	want = append(want, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	want = append(want, 0x00, 0x00, 0x03, 0xff, 0xff, 0x01, 0x02, 0x00, 0x00)
+	if got := s.frame(); !bytes.Equal(got, want) {
+		t.Errorf("frame is %x, want %x", got, want)
+	}
+}
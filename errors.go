@@ -0,0 +1,85 @@
+package hue
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error types as reported by the bridge.
+const (
+	ErrorTypeUnauthorized           = 1   // unauthorized user
+	ErrorTypeInvalidJSON            = 2   // body contains invalid json
+	ErrorTypeResourceNotAvailable   = 3   // resource is not available
+	ErrorTypeMethodNotAvailable     = 4   // method is not available for the resource
+	ErrorTypeMissingParameters      = 5   // missing parameters in body
+	ErrorTypeParameterNotAvailable  = 6   // parameter is not available
+	ErrorTypeInvalidValue           = 7   // invalid value for parameter
+	ErrorTypeParameterNotModifiable = 8   // parameter is not modifiable
+	ErrorTypeLinkButtonNotPressed   = 101 // link button not pressed
+	ErrorTypeDeviceIsOff            = 201 // parameter is not modifiable, the device is set to off
+	ErrorTypeInternalError          = 901 // internal error
+)
+
+// APIError is an error reported by the bridge.
+type APIError struct {
+	Type        uint   `json:"type"`        // Type of the error, see the ErrorType constants.
+	Address     string `json:"address"`     // Resource or parameter on which the error occurred.
+	Description string `json:"description"` // Description of the error.
+}
+
+// Error returns the description of the error as given by the bridge.
+func (e *APIError) Error() string {
+	return e.Description
+}
+
+// APIErrors holds multiple errors reported by the bridge for a single request,
+// e.g. for each invalid parameter in a request body.
+type APIErrors []*APIError
+
+// Error returns the descriptions of all errors, separated by semicolons.
+func (e APIErrors) Error() string {
+	descriptions := make([]string, 0, len(e))
+	for _, apiError := range e {
+		descriptions = append(descriptions, apiError.Error())
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// Unwrap returns the individual errors, so errors.As can be used to find a specific *APIError.
+func (e APIErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, apiError := range e {
+		errs = append(errs, apiError)
+	}
+	return errs
+}
+
+// TransportError is returned when the bridge responds with an http status other than 200 OK.
+// This usually means the request did not reach the api, e.g. because the IP does not point to a bridge.
+type TransportError struct {
+	StatusCode int    // http status code of the response
+	Body       string // (start of) the body of the response
+}
+
+// Error returns the status code and body of the response.
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("bridge responded with http status %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// isAPIErrorType reports whether err is an *APIError of the given type.
+func isAPIErrorType(err error, errorType uint) bool {
+	var apiError *APIError
+	return errors.As(err, &apiError) && apiError.Type == errorType
+}
+
+// IsUnauthorized reports whether err is an *APIError indicating an unauthorized user.
+func IsUnauthorized(err error) bool {
+	return isAPIErrorType(err, ErrorTypeUnauthorized)
+}
+
+// IsLinkButtonNotPressed reports whether err is an *APIError indicating the link button was not pressed.
+func IsLinkButtonNotPressed(err error) bool {
+	return isAPIErrorType(err, ErrorTypeLinkButtonNotPressed)
+}
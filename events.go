@@ -0,0 +1,209 @@
+package hue
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event types as sent on the v2 event stream.
+const (
+	EventTypeAdd    = "add"    // resources were added
+	EventTypeUpdate = "update" // resources were changed, Data holds only the changed properties
+	EventTypeDelete = "delete" // resources were deleted
+	EventTypeError  = "error"  // resources reported an error
+
+	// The following types are not sent by the bridge, Subscribe reports the health of the stream with them.
+	EventTypeDisconnected = "disconnected" // the stream dropped, Subscribe is reconnecting
+	EventTypeReconnected  = "reconnected"  // the stream was re-opened after it dropped, events may have been missed
+)
+
+// Event is a change reported by the bridge on the v2 event stream.
+type Event struct {
+	ID           string          `json:"id"`           // id of the event
+	Type         string          `json:"type"`         // type of the event, see the EventType constants
+	CreationTime time.Time       `json:"creationtime"` // time at which the event was created
+	Data         []EventResource `json:"data"`         // resources the event applies to
+}
+
+// EventResource is a resource (or the changed part of it) included in an Event.
+type EventResource struct {
+	ID   string          `json:"id"`    // id of the resource
+	IDV1 string          `json:"id_v1"` // path of the resource in the v1 api, e.g. “/lights/1”
+	Type string          `json:"type"`  // type of the resource, e.g. “light” or “motion”
+	Raw  json.RawMessage `json:"-"`     // complete json of the resource, to decode type specific properties
+}
+
+// UnmarshalJSON decodes the resource and keeps the complete json in Raw.
+func (r *EventResource) UnmarshalJSON(data []byte) error {
+	type plain EventResource // plain has no UnmarshalJSON method
+	err := json.Unmarshal(data, (*plain)(r))
+	if err != nil {
+		return err
+	}
+	r.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Backoff bounds for reconnecting to the event stream
+const (
+	eventStreamMinBackoff = time.Second
+	eventStreamMaxBackoff = time.Minute
+)
+
+// Subscribe opens the v2 event stream of the bridge and sends the received events on the returned channel.
+// When the stream drops, an event with EventTypeDisconnected is sent and the stream is re-opened automatically,
+// with jittered exponential backoff of up to a minute. Once it is re-opened, an event with EventTypeReconnected is sent.
+// The channel is closed when the context is cancelled, also while waiting to reconnect.
+// Close closes the stream and its channel as well.
+// An error is returned when the stream cannot be opened initially, ErrBridgeClosed after Close was called.
+func (b *Bridge) Subscribe(ctx context.Context) (<-chan Event, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	untrack, err := b.Track(func() error {
+		cancel()
+		return nil
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	body, err := b.openEventStream(ctx)
+	if err != nil {
+		untrack()
+		cancel()
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer untrack()
+		defer cancel()
+		defer close(events)
+
+		backoff := eventStreamMinBackoff
+		for {
+			if b.readEventStream(ctx, body, events) {
+				// events were received, the stream was healthy before it dropped
+				backoff = eventStreamMinBackoff
+			}
+			body.Close()
+			if !sendStreamEvent(ctx, events, EventTypeDisconnected) {
+				return
+			}
+
+			for {
+				timer := time.NewTimer(jitter(backoff))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+				backoff *= 2
+				if backoff > eventStreamMaxBackoff {
+					backoff = eventStreamMaxBackoff
+				}
+
+				body, err = b.openEventStream(ctx)
+				if err == nil {
+					break
+				}
+			}
+			if !sendStreamEvent(ctx, events, EventTypeReconnected) {
+				body.Close()
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// jitter returns a random duration between half of d and d, so clients don't reconnect in lockstep.
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// sendStreamEvent sends an event of the given type, reporting the health of the stream, on the channel.
+// It reports false when the context was cancelled before the event could be sent.
+func sendStreamEvent(ctx context.Context, events chan<- Event, eventType string) bool {
+	select {
+	case events <- Event{Type: eventType, CreationTime: time.Now()}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// openEventStream opens the event stream and returns the body of the response.
+func (b *Bridge) openEventStream(ctx context.Context) (io.ReadCloser, error) {
+	client, err := b.v2Client()
+	if err != nil {
+		return nil, err
+	}
+	// the stream stays open indefinitely, so the client timeout must not apply
+	streamClient := *client
+	streamClient.Timeout = 0
+
+	ip, username := b.credentials()
+	request, err := http.NewRequestWithContext(ctx, "GET", "https://"+ip+"/eventstream/clip/v2", nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("hue-application-key", username)
+	request.Header.Set("Accept", "text/event-stream")
+	response, err := b.do(&streamClient, request)
+	if err != nil {
+		return nil, err
+	}
+	err = checkResponse(response)
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// readEventStream reads server-sent events from body and sends the contained events on the channel,
+// until the stream ends or the context is cancelled. It reports whether any event was received.
+func (b *Bridge) readEventStream(ctx context.Context, body io.Reader, events chan<- Event) bool {
+	received := false
+	reader := bufio.NewReader(body)
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return received
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			continue
+		}
+		if len(line) > 0 || data.Len() == 0 {
+			// other fields (id, comments) are not used
+			continue
+		}
+
+		// an empty line completes a message, its data holds a batch of events
+		batch := make([]Event, 0, 1)
+		err = json.Unmarshal([]byte(data.String()), &batch)
+		data.Reset()
+		if err != nil {
+			continue
+		}
+		for _, event := range batch {
+			select {
+			case events <- event:
+				received = true
+			case <-ctx.Done():
+				return received
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package hue
+
+// Gamut is the triangle of colors in CIE color space that a light can reproduce.
+// Each corner holds the x and y coordinates of the most saturated red, green and blue the light can show.
+type Gamut struct {
+	Red   [2]float64 // x and y coordinates of the red corner
+	Green [2]float64 // x and y coordinates of the green corner
+	Blue  [2]float64 // x and y coordinates of the blue corner
+}
+
+// The color gamuts of the hue color lights.
+var (
+	GamutA = Gamut{Red: [2]float64{0.704, 0.296}, Green: [2]float64{0.2151, 0.7106}, Blue: [2]float64{0.138, 0.08}} // LivingColors and LightStrips
+	GamutB = Gamut{Red: [2]float64{0.675, 0.322}, Green: [2]float64{0.409, 0.518}, Blue: [2]float64{0.167, 0.04}}   // first generation hue bulbs
+	GamutC = Gamut{Red: [2]float64{0.6915, 0.3083}, Green: [2]float64{0.17, 0.7}, Blue: [2]float64{0.1532, 0.0475}} // later generation hue bulbs and LightStrips plus
+)
+
+// modelGamuts maps the model ids of color lights to their gamut
+var modelGamuts = map[string]Gamut{
+	"LLC001": GamutA, "LLC005": GamutA, "LLC006": GamutA, "LLC007": GamutA, "LLC010": GamutA,
+	"LLC011": GamutA, "LLC012": GamutA, "LLC013": GamutA, "LLC014": GamutA, "LST001": GamutA,
+	"LCT001": GamutB, "LCT002": GamutB, "LCT003": GamutB, "LCT007": GamutB, "LLM001": GamutB,
+	"LCT010": GamutC, "LCT011": GamutC, "LCT012": GamutC, "LCT014": GamutC, "LCT015": GamutC,
+	"LCT016": GamutC, "LLC020": GamutC, "LST002": GamutC, "LCA001": GamutC, "LCA002": GamutC,
+	"LCA003": GamutC,
+}
+
+// GamutForModel returns the gamut of the light with given model id, e.g. “LCT001”.
+// The boolean is false when the model is not a known color light.
+func GamutForModel(modelID string) (Gamut, bool) {
+	g, ok := modelGamuts[modelID]
+	return g, ok
+}
+
+// Contains reports whether the x and y coordinates lie within the gamut.
+func (g Gamut) Contains(x, y float64) bool {
+	p := [2]float64{x, y}
+	d1 := cross(g.Red, g.Green, p)
+	d2 := cross(g.Green, g.Blue, p)
+	d3 := cross(g.Blue, g.Red, p)
+	hasNegative := d1 < 0 || d2 < 0 || d3 < 0
+	hasPositive := d1 > 0 || d2 > 0 || d3 > 0
+	return !(hasNegative && hasPositive)
+}
+
+// ClosestInGamut returns the x and y coordinates within the gamut that are closest to the given coordinates.
+// Coordinates that already lie within the gamut are returned unchanged.
+func ClosestInGamut(x, y float64, g Gamut) (float64, float64) {
+	if g.Contains(x, y) {
+		return x, y
+	}
+	p := [2]float64{x, y}
+	closest := closestOnSegment(g.Red, g.Green, p)
+	best := distanceSquared(closest, p)
+	for _, edge := range [][2][2]float64{{g.Green, g.Blue}, {g.Blue, g.Red}} {
+		candidate := closestOnSegment(edge[0], edge[1], p)
+		if d := distanceSquared(candidate, p); d < best {
+			closest, best = candidate, d
+		}
+	}
+	return closest[0], closest[1]
+}
+
+// cross returns the z component of the cross product of b-a and p-a, its sign tells on which side of ab p lies.
+func cross(a, b, p [2]float64) float64 {
+	return (b[0]-a[0])*(p[1]-a[1]) - (b[1]-a[1])*(p[0]-a[0])
+}
+
+// closestOnSegment returns the point on the line segment ab that is closest to p.
+func closestOnSegment(a, b, p [2]float64) [2]float64 {
+	ab := [2]float64{b[0] - a[0], b[1] - a[1]}
+	t := ((p[0]-a[0])*ab[0] + (p[1]-a[1])*ab[1]) / (ab[0]*ab[0] + ab[1]*ab[1])
+	t = clampUnit(t)
+	return [2]float64{a[0] + t*ab[0], a[1] + t*ab[1]}
+}
+
+// distanceSquared returns the squared distance between a and b.
+func distanceSquared(a, b [2]float64) float64 {
+	return (a[0]-b[0])*(a[0]-b[0]) + (a[1]-b[1])*(a[1]-b[1])
+}
@@ -0,0 +1,14 @@
+module github.com/GeertJohan/go.hue
+
+go 1.21
+
+require (
+	github.com/davecgh/go-spew v1.1.1
+	github.com/pion/dtls/v2 v2.2.7
+)
+
+require (
+	github.com/pion/logging v0.2.2 // indirect
+	github.com/pion/transport/v2 v2.2.1 // indirect
+	golang.org/x/crypto v0.8.0 // indirect
+)
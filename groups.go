@@ -0,0 +1,360 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// AllLightsGroupID is the id of the special group that contains all lights known by the bridge.
+const AllLightsGroupID = "0"
+
+// Group types that can be created on the bridge.
+const (
+	GroupTypeLightGroup    = "LightGroup"    // plain group of lights, not shown in the app
+	GroupTypeRoom          = "Room"          // room, a light can be in only one room
+	GroupTypeZone          = "Zone"          // zone, a light can be in multiple zones
+	GroupTypeEntertainment = "Entertainment" // entertainment area, used for streaming
+)
+
+// GroupClasses are the classes a room or zone can have, as documented for the api.
+// The app uses the class to choose the icon of the room or zone.
+var GroupClasses = []string{
+	"Living room", "Kitchen", "Dining", "Bedroom", "Kids bedroom", "Bathroom", "Nursery", "Recreation",
+	"Office", "Gym", "Hallway", "Toilet", "Front door", "Garage", "Terrace", "Garden", "Driveway", "Carport",
+	"Home", "Downstairs", "Upstairs", "Top floor", "Attic", "Guest room", "Staircase", "Lounge", "Man cave",
+	"Computer", "Studio", "Music", "TV", "Reading", "Closet", "Storage", "Laundry room", "Balcony", "Porch",
+	"Barbecue", "Pool", "Free", "Other",
+}
+
+// ErrGroupNotFound is returned when a group lookup does not match any group on the bridge.
+var ErrGroupNotFound = errors.New("group not found")
+
+// Group points to a specific group of lights on a specific hue bridge
+type Group struct {
+	bridge *Bridge      // bridge on which the group is defined
+	ID     string       `json:"-"`                // id of the group
+	Name   string       `json:"name"`             // name of the group
+	Lights []string     `json:"lights"`           // ids of the lights in the group
+	Type   string       `json:"type,omitempty"`   // type of the group, e.g. “LightGroup” or “Room”
+	Class  string       `json:"class,omitempty"`  // class of a room or zone, e.g. “Living room”. Empty for other groups.
+	Stream *GroupStream `json:"stream,omitempty"` // streaming state of an entertainment area, nil for other groups
+	State  *GroupState  `json:"state,omitempty"`  // aggregate on-state of the lights, as known when the group was retrieved
+}
+
+// GroupState holds the aggregate on-state of the lights in a group.
+type GroupState struct {
+	AllOn bool `json:"all_on"` // whether all lights in the group are on
+	AnyOn bool `json:"any_on"` // whether at least one light in the group is on
+}
+
+// AnyOn reports whether at least one light in the group was on when the group was retrieved.
+// It is false when the bridge did not report the state of the group.
+func (g *Group) AnyOn() bool {
+	return g.State != nil && g.State.AnyOn
+}
+
+// AllOn reports whether all lights in the group were on when the group was retrieved.
+// It is false when the bridge did not report the state of the group.
+func (g *Group) AllOn() bool {
+	return g.State != nil && g.State.AllOn
+}
+
+// GetAllGroups returns all groups defined on the bridge, sorted by their numeric ID.
+// The special group with AllLightsGroupID is not included.
+func (b *Bridge) GetAllGroups() ([]*Group, error) {
+	return b.GetAllGroupsContext(context.Background())
+}
+
+// GetAllGroupsContext is like GetAllGroups, the request is bound to the given context.
+func (b *Bridge) GetAllGroupsContext(ctx context.Context) ([]*Group, error) {
+	groupsMap := map[string]*Group{}
+	err := b.getJSON(ctx, b.URL()+"/groups", &groupsMap)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]*Group, 0, len(groupsMap))
+	for groupID, group := range groupsMap {
+		group.bridge = b
+		group.ID = groupID
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return lessNumericID(groups[i].ID, groups[j].ID)
+	})
+	return groups, nil
+}
+
+// GetGroupByName returns the first group whose name equals the given name, e.g. a room created in the app.
+// Names are compared case-sensitive. ErrGroupNotFound is returned when no group matches.
+func (b *Bridge) GetGroupByName(name string) (*Group, error) {
+	return b.GetGroupByNameContext(context.Background(), name)
+}
+
+// GetGroupByNameContext is like GetGroupByName, the request is bound to the given context.
+func (b *Bridge) GetGroupByNameContext(ctx context.Context, name string) (*Group, error) {
+	groups, err := b.GetAllGroupsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if group.Name == name {
+			return group, nil
+		}
+	}
+	return nil, ErrGroupNotFound
+}
+
+// GroupsByClass returns all groups defined on the bridge by their Class, e.g. “Bedroom” or “Kitchen”.
+// Groups without a class, like plain light groups, are listed under the empty string.
+// Within a class, the groups are sorted by their numeric ID.
+func (b *Bridge) GroupsByClass() (map[string][]*Group, error) {
+	return b.GroupsByClassContext(context.Background())
+}
+
+// GroupsByClassContext is like GroupsByClass, the request is bound to the given context.
+func (b *Bridge) GroupsByClassContext(ctx context.Context) (map[string][]*Group, error) {
+	groups, err := b.GetAllGroupsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groupsByClass := make(map[string][]*Group)
+	for _, group := range groups {
+		groupsByClass[group.Class] = append(groupsByClass[group.Class], group)
+	}
+	return groupsByClass, nil
+}
+
+// CreateGroup creates a new group with the given name, containing the given lights.
+// The returned group has the ID that was assigned by the bridge. At least one light id must be given.
+func (b *Bridge) CreateGroup(name string, lightIDs []string) (*Group, error) {
+	return b.CreateGroupContext(context.Background(), name, lightIDs)
+}
+
+// CreateGroupContext is like CreateGroup, the request is bound to the given context.
+func (b *Bridge) CreateGroupContext(ctx context.Context, name string, lightIDs []string) (*Group, error) {
+	if len(lightIDs) == 0 {
+		return nil, errors.New("a group must contain at least one light")
+	}
+	return b.createGroup(ctx, &Group{Name: name, Lights: lightIDs})
+}
+
+// CreateRoom creates a new room with the given name and class, containing the given lights.
+// Class must be one of GroupClasses, when empty “Other” is used. A light can only be in one room,
+// the bridge returns an error when one of the lights is already in another room.
+func (b *Bridge) CreateRoom(name string, class string, lightIDs []string) (*Group, error) {
+	return b.CreateRoomContext(context.Background(), name, class, lightIDs)
+}
+
+// CreateRoomContext is like CreateRoom, the request is bound to the given context.
+func (b *Bridge) CreateRoomContext(ctx context.Context, name string, class string, lightIDs []string) (*Group, error) {
+	return b.createClassifiedGroup(ctx, GroupTypeRoom, name, class, lightIDs)
+}
+
+// CreateZone creates a new zone with the given name and class, containing the given lights.
+// Class must be one of GroupClasses, when empty “Other” is used. At least one light id must be given.
+func (b *Bridge) CreateZone(name string, class string, lightIDs []string) (*Group, error) {
+	return b.CreateZoneContext(context.Background(), name, class, lightIDs)
+}
+
+// CreateZoneContext is like CreateZone, the request is bound to the given context.
+func (b *Bridge) CreateZoneContext(ctx context.Context, name string, class string, lightIDs []string) (*Group, error) {
+	if len(lightIDs) == 0 {
+		return nil, errors.New("a zone must contain at least one light")
+	}
+	return b.createClassifiedGroup(ctx, GroupTypeZone, name, class, lightIDs)
+}
+
+// createClassifiedGroup validates the class and creates a room or zone
+func (b *Bridge) createClassifiedGroup(ctx context.Context, groupType string, name string, class string, lightIDs []string) (*Group, error) {
+	if len(class) == 0 {
+		class = "Other"
+	}
+	if !isGroupClass(class) {
+		return nil, fmt.Errorf("invalid group class %q, must be one of %q", class, GroupClasses)
+	}
+	if lightIDs == nil {
+		lightIDs = []string{}
+	}
+	return b.createGroup(ctx, &Group{Name: name, Lights: lightIDs, Type: groupType, Class: class})
+}
+
+// isGroupClass reports whether class is one of GroupClasses
+func isGroupClass(class string) bool {
+	for _, groupClass := range GroupClasses {
+		if groupClass == class {
+			return true
+		}
+	}
+	return false
+}
+
+// createGroup creates the group on the bridge and sets the ID that was assigned by the bridge.
+func (b *Bridge) createGroup(ctx context.Context, group *Group) (*Group, error) {
+	group.bridge = b
+	apiResponseSlice, err := b.sendAPIRequest(ctx, "POST", b.URL()+"/groups", group)
+	if err != nil {
+		return nil, err
+	}
+	group.ID, err = createdID(apiResponseSlice)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteGroup deletes the group with the given id from the bridge.
+func (b *Bridge) DeleteGroup(id string) error {
+	return b.DeleteGroupContext(context.Background(), id)
+}
+
+// DeleteGroupContext is like DeleteGroup, the request is bound to the given context.
+func (b *Bridge) DeleteGroupContext(ctx context.Context, id string) error {
+	_, err := b.sendAPIRequest(ctx, "DELETE", b.URL()+"/groups/"+id, nil)
+	return err
+}
+
+// SetGroupLights replaces the lights in the group with the given id by the given lights.
+// Unlike recreating the group, this keeps the scenes of the group. All light ids are checked to exist
+// before the group is changed, an error wrapping ErrLightNotFound is returned for an unknown light.
+func (b *Bridge) SetGroupLights(groupID string, lightIDs []string) error {
+	return b.SetGroupLightsContext(context.Background(), groupID, lightIDs)
+}
+
+// SetGroupLightsContext is like SetGroupLights, the requests are bound to the given context.
+func (b *Bridge) SetGroupLightsContext(ctx context.Context, groupID string, lightIDs []string) error {
+	lightsMap := map[string]interface{}{} // only the ids are used
+	err := b.getJSON(ctx, b.URL()+"/lights", &lightsMap)
+	if err != nil {
+		return err
+	}
+	for _, lightID := range lightIDs {
+		if _, ok := lightsMap[lightID]; !ok {
+			return fmt.Errorf("light %s: %w", lightID, ErrLightNotFound)
+		}
+	}
+	if lightIDs == nil {
+		lightIDs = []string{}
+	}
+	_, err = b.sendAPIRequest(ctx, "PUT", b.URL()+"/groups/"+groupID, map[string][]string{"lights": lightIDs})
+	return err
+}
+
+// AddLightToGroup adds the light to the group with the given id, keeping the lights already in the group.
+// Nothing is changed when the light already is in the group.
+func (b *Bridge) AddLightToGroup(groupID string, lightID string) error {
+	return b.AddLightToGroupContext(context.Background(), groupID, lightID)
+}
+
+// AddLightToGroupContext is like AddLightToGroup, the requests are bound to the given context.
+func (b *Bridge) AddLightToGroupContext(ctx context.Context, groupID string, lightID string) error {
+	group, err := b.getGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	for _, groupLightID := range group.Lights {
+		if groupLightID == lightID {
+			return nil
+		}
+	}
+	return b.SetGroupLightsContext(ctx, groupID, append(group.Lights, lightID))
+}
+
+// RemoveLightFromGroup removes the light from the group with the given id, keeping the other lights in the group.
+// Nothing is changed when the light is not in the group.
+func (b *Bridge) RemoveLightFromGroup(groupID string, lightID string) error {
+	return b.RemoveLightFromGroupContext(context.Background(), groupID, lightID)
+}
+
+// RemoveLightFromGroupContext is like RemoveLightFromGroup, the requests are bound to the given context.
+func (b *Bridge) RemoveLightFromGroupContext(ctx context.Context, groupID string, lightID string) error {
+	group, err := b.getGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	lightIDs := make([]string, 0, len(group.Lights))
+	for _, groupLightID := range group.Lights {
+		if groupLightID != lightID {
+			lightIDs = append(lightIDs, groupLightID)
+		}
+	}
+	if len(lightIDs) == len(group.Lights) {
+		return nil
+	}
+	return b.SetGroupLightsContext(ctx, groupID, lightIDs)
+}
+
+// getGroup fetches the group with the given id from the bridge.
+func (b *Bridge) getGroup(ctx context.Context, groupID string) (*Group, error) {
+	group := &Group{}
+	err := b.getJSON(ctx, b.URL()+"/groups/"+groupID, group)
+	if err != nil {
+		return nil, err
+	}
+	group.bridge = b
+	group.ID = groupID
+	return group, nil
+}
+
+// SetState applies the given state change to all lights in the group at once.
+// Relative changes outside their range are rejected without contacting the bridge, see LightStateChange.Validate.
+func (g *Group) SetState(change LightStateChange) error {
+	return g.SetStateContext(context.Background(), change)
+}
+
+// SetStateContext is like SetState, the request is bound to the given context.
+func (g *Group) SetStateContext(ctx context.Context, change LightStateChange) error {
+	err := change.Validate()
+	if err != nil {
+		return err
+	}
+	return g.bridge.sendStateChange(ctx, g.bridge.URL()+"/groups/"+g.ID+"/action", change)
+}
+
+// AllLightsGroup returns the special group that contains all lights known by the bridge.
+func (b *Bridge) AllLightsGroup() *Group {
+	return &Group{bridge: b, ID: AllLightsGroupID}
+}
+
+// On turns all lights in the group on.
+func (g *Group) On() error {
+	return g.OnContext(context.Background())
+}
+
+// OnContext is like On, the request is bound to the given context.
+func (g *Group) OnContext(ctx context.Context) error {
+	on := true
+	return g.SetStateContext(ctx, LightStateChange{On: &on})
+}
+
+// Off turns all lights in the group off.
+func (g *Group) Off() error {
+	return g.OffContext(context.Background())
+}
+
+// OffContext is like Off, the request is bound to the given context.
+func (g *Group) OffContext(ctx context.Context) error {
+	on := false
+	return g.SetStateContext(ctx, LightStateChange{On: &on})
+}
+
+// AllOn turns all lights known by the bridge on, using a single request.
+func (b *Bridge) AllOn() error {
+	return b.AllOnContext(context.Background())
+}
+
+// AllOnContext is like AllOn, the request is bound to the given context.
+func (b *Bridge) AllOnContext(ctx context.Context) error {
+	return b.AllLightsGroup().OnContext(ctx)
+}
+
+// AllOff turns all lights known by the bridge off, using a single request.
+func (b *Bridge) AllOff() error {
+	return b.AllOffContext(context.Background())
+}
+
+// AllOffContext is like AllOff, the request is bound to the given context.
+func (b *Bridge) AllOffContext(ctx context.Context) error {
+	return b.AllLightsGroup().OffContext(ctx)
+}
@@ -1,5 +1,22 @@
 package hue
 
 import (
-//	"fmt"
+	"strconv"
 )
+
+// lessNumericID reports whether resource id a sorts before id b.
+// The bridge uses numeric strings as ids, these are compared by their numeric value.
+// Ids that are not numeric are compared as strings and sort after the numeric ids.
+func lessNumericID(a, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return na < nb
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	}
+	return a < b
+}
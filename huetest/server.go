@@ -0,0 +1,318 @@
+// Package huetest provides a fake hue bridge for testing code that uses the hue package, without hue hardware.
+//
+// The fake bridge serves the (public) configuration, lights and light state endpoints of the bridge api.
+// State changes sent to the lights are applied, and every request that modifies the bridge is recorded,
+// so tests can assert on the commands their code issued.
+package huetest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/GeertJohan/go.hue"
+)
+
+// DefaultUsername is the username accepted by a new Server.
+const DefaultUsername = "huetest"
+
+// Command is a modifying request (POST, PUT or DELETE) received by the Server.
+type Command struct {
+	Method string          // http method of the request
+	Path   string          // path of the request, relative to /api/<username>, e.g. “/lights/1/state”
+	Body   json.RawMessage // body of the request
+}
+
+// Server is a fake hue bridge, serving the bridge api over http.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	username string
+	config   hue.BridgeConfiguration
+	lights   map[string]*hue.LightAttributes
+	errors   map[string]hue.APIError
+	commands []Command
+}
+
+// NewServer starts a new fake bridge without lights, accepting DefaultUsername.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		username: DefaultUsername,
+		lights:   make(map[string]*hue.LightAttributes),
+		errors:   make(map[string]hue.APIError),
+	}
+	s.config.Name = "huetest"
+	s.config.BridgeID = "001788FFFE000000"
+	s.config.Swversion = "1940094000"
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Bridge returns a hue.Bridge that talks to the fake bridge, with the accepted username set.
+func (s *Server) Bridge() *hue.Bridge {
+	b := hue.NewBridge(strings.TrimPrefix(s.URL, "http://"))
+	b.Username = s.Username()
+	b.HTTPClient = s.Client()
+	return b
+}
+
+// Username returns the username accepted by the fake bridge.
+func (s *Server) Username() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.username
+}
+
+// SetUsername sets the username accepted by the fake bridge, and returned when a new user is created.
+func (s *Server) SetUsername(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username = username
+}
+
+// SetConfig sets the configuration served by the fake bridge.
+func (s *Server) SetConfig(config hue.BridgeConfiguration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+// SetLight adds the light with given id to the fake bridge, or replaces it.
+func (s *Server) SetLight(id string, attributes hue.LightAttributes) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lights[id] = &attributes
+}
+
+// Light returns the current attributes of the light with given id, including state changes applied to it.
+func (s *Server) Light(id string) (hue.LightAttributes, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attributes, ok := s.lights[id]
+	if !ok {
+		return hue.LightAttributes{}, false
+	}
+	return *attributes, true
+}
+
+// SetError makes the fake bridge answer requests with given method and path, e.g. “/lights/1”, with the error.
+// The requests are still recorded as commands.
+func (s *Server) SetError(method, path string, apiError hue.APIError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[method+" "+path] = apiError
+}
+
+// Commands returns the modifying requests received by the fake bridge, in order of arrival.
+func (s *Server) Commands() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Command(nil), s.commands...)
+}
+
+// CommandsTo returns the modifying requests received for the given path, e.g. “/lights/1/state”.
+func (s *Server) CommandsTo(path string) []Command {
+	var commands []Command
+	for _, command := range s.Commands() {
+		if command.Path == path {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// ResetCommands forgets the requests received so far.
+func (s *Server) ResetCommands() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = nil
+}
+
+// serveHTTP handles all requests to the fake bridge
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	// creating a user is the only request without username
+	if r.URL.Path == "/api" || r.URL.Path == "/api/" {
+		if r.Method != "POST" {
+			writeError(w, hue.ErrorTypeMethodNotAvailable, "/", "method, "+r.Method+", not available for resource, /")
+			return
+		}
+		writeJSON(w, []interface{}{map[string]interface{}{"success": map[string]string{"username": s.username}}})
+		return
+	}
+
+	// the public configuration is available without username
+	if r.Method == "GET" && r.URL.Path == "/api/config" {
+		writeJSON(w, hue.PublicConfig{
+			Name:       s.config.Name,
+			BridgeID:   s.config.BridgeID,
+			ModelID:    "BSB002",
+			Mac:        s.config.Mac,
+			Swversion:  s.config.Swversion,
+			APIVersion: "1.46.0",
+		})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "api" {
+		http.NotFound(w, r)
+		return
+	}
+	if parts[1] != s.username {
+		writeError(w, hue.ErrorTypeUnauthorized, "/", "unauthorized user")
+		return
+	}
+	resource := parts[2:]
+	path := "/" + strings.Join(resource, "/")
+
+	if r.Method != "GET" {
+		s.commands = append(s.commands, Command{Method: r.Method, Path: path, Body: json.RawMessage(body)})
+	}
+	if apiError, ok := s.errors[r.Method+" "+path]; ok {
+		writeError(w, apiError.Type, apiError.Address, apiError.Description)
+		return
+	}
+
+	switch {
+	case r.Method == "GET" && path == "/config":
+		writeJSON(w, s.config)
+	case r.Method == "GET" && path == "/lights":
+		writeJSON(w, s.lights)
+	case len(resource) >= 2 && resource[0] == "lights" && s.lights[resource[1]] == nil:
+		writeError(w, hue.ErrorTypeResourceNotAvailable, path, "resource, "+path+", not available")
+	case r.Method == "GET" && len(resource) == 2 && resource[0] == "lights":
+		writeJSON(w, s.lights[resource[1]])
+	case r.Method == "PUT" && len(resource) == 3 && resource[0] == "lights" && resource[2] == "state":
+		s.setLightState(w, path, s.lights[resource[1]], body)
+	case r.Method == "PUT" && len(resource) == 2 && resource[0] == "lights":
+		s.setLightAttributes(w, path, s.lights[resource[1]], body)
+	case r.Method == "GET":
+		writeError(w, hue.ErrorTypeResourceNotAvailable, path, "resource, "+path+", not available")
+	case r.Method == "DELETE" && len(resource) == 2 && resource[0] == "lights":
+		delete(s.lights, resource[1])
+		writeJSON(w, []interface{}{map[string]string{"success": path + " deleted"}})
+	case r.Method == "DELETE":
+		writeJSON(w, []interface{}{map[string]string{"success": path + " deleted"}})
+	case r.Method == "POST":
+		writeJSON(w, []interface{}{map[string]interface{}{"success": map[string]string{"id": "1"}}})
+	default:
+		writeSuccess(w, path, body)
+	}
+}
+
+// setLightState applies a state change to a light
+func (s *Server) setLightState(w http.ResponseWriter, path string, light *hue.LightAttributes, body []byte) {
+	change := hue.LightStateChange{}
+	if err := json.Unmarshal(body, &change); err != nil {
+		writeError(w, hue.ErrorTypeInvalidJSON, path, "body contains invalid json")
+		return
+	}
+	state := &light.State
+	if change.On != nil {
+		state.On = *change.On
+	}
+	if change.Bri != nil {
+		state.Brightness = *change.Bri
+	}
+	if change.BriInc != nil {
+		state.Brightness = uint8(max(1, min(254, int(state.Brightness)+*change.BriInc)))
+	}
+	if change.Hue != nil {
+		state.Hue = *change.Hue
+		state.ColorMode = hue.ColorModeHS
+	}
+	if change.Sat != nil {
+		state.Saturation = *change.Sat
+		state.ColorMode = hue.ColorModeHS
+	}
+	if change.CT != nil {
+		state.CT = *change.CT
+		state.ColorMode = hue.ColorModeCT
+	}
+	if change.XY != nil {
+		state.XY = *change.XY
+		state.ColorMode = hue.ColorModeXY
+	}
+	if change.HueInc != nil {
+		state.Hue = uint16((int(state.Hue) + *change.HueInc + 65536) % 65536)
+		state.ColorMode = hue.ColorModeHS
+	}
+	if change.SatInc != nil {
+		state.Saturation = uint8(max(0, min(254, int(state.Saturation)+*change.SatInc)))
+		state.ColorMode = hue.ColorModeHS
+	}
+	if change.CTInc != nil {
+		state.CT = uint16(max(hue.MinColorTemperature, min(hue.MaxColorTemperature, int(state.CT)+*change.CTInc)))
+		state.ColorMode = hue.ColorModeCT
+	}
+	if change.XYInc != nil {
+		state.XY = hue.XY{
+			max(0, min(1, state.XY[0]+change.XYInc[0])),
+			max(0, min(1, state.XY[1]+change.XYInc[1])),
+		}
+		state.ColorMode = hue.ColorModeXY
+	}
+	if change.Effect != nil {
+		state.Effect = *change.Effect
+	}
+	if change.Alert != nil {
+		state.Alert = *change.Alert
+	}
+	writeSuccess(w, path, body)
+}
+
+// setLightAttributes applies a change of attributes (the name) to a light
+func (s *Server) setLightAttributes(w http.ResponseWriter, path string, light *hue.LightAttributes, body []byte) {
+	attributes := struct {
+		Name *string `json:"name"`
+	}{}
+	if err := json.Unmarshal(body, &attributes); err != nil {
+		writeError(w, hue.ErrorTypeInvalidJSON, path, "body contains invalid json")
+		return
+	}
+	if attributes.Name != nil {
+		light.Name = *attributes.Name
+	}
+	writeSuccess(w, path, body)
+}
+
+// writeSuccess writes a success item for each of the values in the json object body, like the bridge does.
+func writeSuccess(w http.ResponseWriter, path string, body []byte) {
+	values := map[string]interface{}{}
+	_ = json.Unmarshal(body, &values)
+	response := make([]interface{}, 0, len(values))
+	for key, value := range values {
+		response = append(response, map[string]interface{}{"success": map[string]interface{}{path + "/" + key: value}})
+	}
+	writeJSON(w, response)
+}
+
+// writeError writes an error item, like the bridge does.
+func writeError(w http.ResponseWriter, errorType uint, address string, description string) {
+	writeJSON(w, []interface{}{map[string]interface{}{"error": hue.APIError{
+		Type:        errorType,
+		Address:     address,
+		Description: description,
+	}}})
+}
+
+// writeJSON writes v as json response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	_ = json.NewEncoder(w).Encode(v)
+}
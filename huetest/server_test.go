@@ -0,0 +1,132 @@
+package huetest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/GeertJohan/go.hue"
+	"github.com/GeertJohan/go.hue/huetest"
+)
+
+// newBridge returns a bridge that talks to srv, created like users of the hue package do.
+func newBridge(srv *huetest.Server) *hue.Bridge {
+	return hue.NewBridge(strings.TrimPrefix(srv.URL, "http://"), hue.WithUsername(srv.Username()))
+}
+
+func TestServerGetAllLights(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	srv.SetLight("2", hue.LightAttributes{Name: "Desk", ModelID: "LCT015", State: hue.LightState{Brightness: 50, Reachable: true}})
+	srv.SetLight("1", hue.LightAttributes{Name: "Lamp", Type: hue.LightTypeExtendedColor, State: hue.LightState{On: true, Brightness: 200}})
+
+	lights, err := newBridge(srv).GetAllLights()
+	if err != nil {
+		t.Fatalf("GetAllLights: %v", err)
+	}
+	if len(lights) != 2 {
+		t.Fatalf("GetAllLights returned %d lights, want 2", len(lights))
+	}
+	lamp, desk := lights[0], lights[1]
+	if lamp.ID != "1" || lamp.Name != "Lamp" || lamp.Type != hue.LightTypeExtendedColor || !lamp.State.On || lamp.State.Brightness != 200 {
+		t.Errorf("first light is %+v, want light 1 named Lamp, on with brightness 200", lamp)
+	}
+	if desk.ID != "2" || desk.Name != "Desk" || desk.ModelID != "LCT015" || desk.State.On || desk.State.Brightness != 50 || !desk.State.Reachable {
+		t.Errorf("second light is %+v, want light 2 named Desk, off with brightness 50", desk)
+	}
+}
+
+func TestServerSetState(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	srv.SetLight("1", hue.LightAttributes{Name: "Lamp"})
+
+	lights, err := newBridge(srv).GetAllLights()
+	if err != nil {
+		t.Fatalf("GetAllLights: %v", err)
+	}
+	on, bri, ct := true, uint8(120), uint16(300)
+	err = lights[0].SetState(hue.LightStateChange{On: &on, Bri: &bri, CT: &ct})
+	if err != nil {
+		t.Fatalf("SetState: %v", err)
+	}
+
+	attributes, ok := srv.Light("1")
+	if !ok {
+		t.Fatal("light 1 is missing after SetState")
+	}
+	if state := attributes.State; !state.On || state.Brightness != 120 || state.CT != 300 || state.ColorMode != hue.ColorModeCT {
+		t.Errorf("state after SetState is %+v, want on with brightness 120 and ct 300", state)
+	}
+
+	commands := srv.Commands()
+	if len(commands) != 1 {
+		t.Fatalf("recorded %d commands, want 1", len(commands))
+	}
+	if commands[0].Method != "PUT" || commands[0].Path != "/lights/1/state" {
+		t.Errorf("recorded %s %s, want PUT /lights/1/state", commands[0].Method, commands[0].Path)
+	}
+	if string(commands[0].Body) != `{"on":true,"bri":120,"ct":300}` {
+		t.Errorf("recorded body %s, want the sent state change", commands[0].Body)
+	}
+	if len(srv.CommandsTo("/lights/1/state")) != 1 || len(srv.CommandsTo("/lights/2/state")) != 0 {
+		t.Errorf("CommandsTo does not select the commands by path")
+	}
+
+	srv.ResetCommands()
+	if len(srv.Commands()) != 0 {
+		t.Errorf("Commands after ResetCommands returned %d commands, want 0", len(srv.Commands()))
+	}
+}
+
+func TestServerLightsJSON(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	srv.SetLight("1", hue.LightAttributes{Name: "Lamp", Type: hue.LightTypeDimmable, State: hue.LightState{On: true, Brightness: 10}})
+
+	response, err := http.Get(srv.URL + "/api/" + srv.Username() + "/lights")
+	if err != nil {
+		t.Fatalf("GET /lights: %v", err)
+	}
+	defer response.Body.Close()
+	lights := map[string]map[string]json.RawMessage{}
+	err = json.NewDecoder(response.Body).Decode(&lights)
+	if err != nil {
+		t.Fatalf("decoding lights: %v", err)
+	}
+	state := map[string]json.RawMessage{}
+	err = json.Unmarshal(lights["1"]["state"], &state)
+	if err != nil {
+		t.Fatalf("light 1 has no state object like the bridge sends: %v", err)
+	}
+	for _, key := range []string{"on", "bri", "hue", "sat", "xy", "ct", "reachable"} {
+		if _, ok := state[key]; !ok {
+			t.Errorf("state of light 1 lacks the key %q, the bridge uses lowercase keys", key)
+		}
+	}
+	if string(lights["1"]["type"]) != `"Dimmable light"` {
+		t.Errorf("light 1 has type %s, want \"Dimmable light\"", lights["1"]["type"])
+	}
+}
+
+func TestServerSetError(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	srv.SetLight("1", hue.LightAttributes{Name: "Lamp"})
+	srv.SetError("PUT", "/lights/1/state", hue.APIError{Type: hue.ErrorTypeDeviceIsOff, Address: "/lights/1/state/bri", Description: "parameter, bri, is not modifiable. Device is set to off."})
+
+	lights, err := newBridge(srv).GetAllLights()
+	if err != nil {
+		t.Fatalf("GetAllLights: %v", err)
+	}
+	err = lights[0].SetBrightness(10)
+	var apiError *hue.APIError
+	if !errors.As(err, &apiError) || apiError.Type != hue.ErrorTypeDeviceIsOff {
+		t.Fatalf("SetBrightness returned %v, want the error set on the server", err)
+	}
+	if len(srv.CommandsTo("/lights/1/state")) != 1 {
+		t.Errorf("the failed command was not recorded")
+	}
+}
@@ -9,25 +9,27 @@ import (
 func main() {
 	fmt.Println("Welcome to huexample")
 
-	brokerDetailsSlice, err := hue.DiscoverBridges()
+	bridges, err := hue.DiscoverBridges()
 	if err != nil {
 		fmt.Println("Error while discovering bridges:", err)
 		return
 	}
 
-	if len(brokerDetailsSlice) == 0 {
-		fmt.Println("No bridge details found. Stopping.")
+	if len(bridges) == 0 {
+		fmt.Println("No bridges found. Stopping.")
 		return
 	}
 
-	fmt.Printf("Found %d bridges:\n", len(brokerDetailsSlice))
-	spew.Dump(brokerDetailsSlice)
+	fmt.Printf("Found %d bridges:\n", len(bridges))
+	for _, bridge := range bridges {
+		fmt.Printf("  ip: %s, id: %s, username: %q\n", bridge.IP, bridge.BridgeID, bridge.Username)
+	}
 
-	fmt.Printf("Continueing with first bridge found, id: %s\n", brokerDetailsSlice[0].ID)
+	fmt.Printf("Continueing with first bridge found, id: %s\n", bridges[0].BridgeID)
 
 	fmt.Println("Going to create user with empty username, bridge will generate a username.")
-	bridge := hue.NewBridge(brokerDetailsSlice[0].InternalIPAddress)
-	newUsername, err := bridge.CreateNewUser("huexample", "")
+	bridge := bridges[0]
+	newUsername, err := bridge.CreateNewUser("huexample#go.hue", "")
 	if err != nil {
 		fmt.Printf("have error: %s\n", err)
 		return
@@ -1,78 +1,647 @@
 package hue
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"net/http"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
 )
 
+// ErrLightNotFound is returned when a light lookup does not match any light on the bridge.
+var ErrLightNotFound = errors.New("light not found")
+
 // Light points to a specific light on a specific hue bridge
 type Light struct {
-	bridge *Bridge // bridge on which the light is connected
-	id     string  // id of the light
+	bridge  *Bridge    // bridge on which the light is connected
+	ID      string     // id of the light
+	Name    string     // name of the light, as known when the light was retrieved from the bridge
+	ModelID string     // hardware model of the light, used to clamp colors to its gamut. Empty when unknown.
+	State   LightState // state of the light, as known when the light was retrieved from the bridge
+
+	Type             string // type of the light, e.g. “Extended color light”, see the LightType constants
+	Swversion        string // software version running on the light
+	ManufacturerName string // manufacturer of the light, e.g. “Signify Netherlands B.V.”
+	ProductName      string // product name of the light, e.g. “Hue color lamp”. Empty for older bridge firmware.
+
+	Capabilities LightCapabilities // capabilities of the light, only reported by bridges with newer firmware
+
+	fetched time.Time // time at which State was retrieved from the bridge, zero when State is unknown
 }
 
-func (l Light) Attributes() (*LightAttributes, error) {
-	resp, err := http.Get(l.bridge.URL() + "/lights/" + l.id)
+// lightStateMaxAge is the age up to which a retrieved State is trusted by Toggle, older states are refreshed first
+const lightStateMaxAge = 2 * time.Second
+
+// Attributes fetches the attributes of the light from the bridge.
+func (l *Light) Attributes() (*LightAttributes, error) {
+	return l.AttributesContext(context.Background())
+}
+
+// AttributesContext is like Attributes, the request is bound to the given context.
+func (l *Light) AttributesContext(ctx context.Context) (*LightAttributes, error) {
+	attributes := &LightAttributes{}
+	err := l.bridge.getJSON(ctx, l.bridge.URL()+"/lights/"+l.ID, attributes)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return attributes, nil
+}
 
-	attributes := &LightAttributes{}
-	err = json.NewDecoder(resp.Body).Decode(attributes)
+// Refresh fetches the current name, model, version information and state of the light from the bridge and updates the Light.
+// Lights that are not reachable are refreshed as well, their State.Reachable is false.
+func (l *Light) Refresh() error {
+	return l.RefreshContext(context.Background())
+}
+
+// RefreshContext is like Refresh, the request is bound to the given context.
+func (l *Light) RefreshContext(ctx context.Context) error {
+	attributes, err := l.AttributesContext(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	l.setAttributes(attributes, time.Now())
+	return nil
+}
 
-	return attributes, nil
+// setAttributes updates the light with the attributes, as retrieved at the given time.
+func (l *Light) setAttributes(attributes *LightAttributes, fetched time.Time) {
+	l.Name = attributes.Name
+	l.ModelID = attributes.ModelID
+	l.State = attributes.State
+	l.Type = attributes.Type
+	l.Swversion = attributes.Swversion
+	l.ManufacturerName = attributes.ManufacturerName
+	l.ProductName = attributes.ProductName
+	l.Capabilities = attributes.Capabilities
+	l.fetched = fetched
+}
+
+// Light types as reported in Light.Type.
+const (
+	LightTypeOnOff         = "On/Off light"            // can only be switched on and off
+	LightTypeDimmable      = "Dimmable light"          // white light with brightness
+	LightTypeColorTemp     = "Color temperature light" // white light with brightness and color temperature
+	LightTypeColor         = "Color light"             // color light with brightness, without color temperature
+	LightTypeExtendedColor = "Extended color light"    // color light with brightness and color temperature
+)
+
+// SupportsColor reports whether the light can show colors, rather than only shades of white.
+// It is derived from the capabilities of the light when the bridge reports them, and from its Type otherwise.
+func (l *Light) SupportsColor() bool {
+	if l.Capabilities.Control.ColorGamutType != "" {
+		return true
+	}
+	return l.Type == LightTypeColor || l.Type == LightTypeExtendedColor
+}
+
+// SetName sets the name of the light.
+//
+// Deprecated: use Rename or RenameContext, which also update the Name field of the light.
+func (l *Light) SetName(newName string) error {
+	return l.Rename(newName)
 }
 
-// SetName sets the name of the light. The given name must have a length between 0 and 32 characters.
-func (l Light) SetName(newName string) error {
-	//++ TODO: check for ascii characters only??
-	if len(newName) > 32 {
-		return errors.New("given name exceeds length limit")
+// Rename sets the name of the light and updates the Name field on success.
+// The name must have a length between 1 and 32 characters, otherwise an error is returned without contacting the bridge.
+func (l *Light) Rename(name string) error {
+	return l.RenameContext(context.Background(), name)
+}
+
+// RenameContext is like Rename, the request is bound to the given context.
+func (l *Light) RenameContext(ctx context.Context, name string) error {
+	if n := utf8.RuneCountInString(name); n < 1 || n > 32 {
+		return errors.New("light name must have a length between 1 and 32 characters")
 	}
 
-	setNameObject := map[string]string{"name": newName}
-	bodyBytes, err := json.Marshal(setNameObject)
+	_, err := l.bridge.sendAPIRequest(ctx, "PUT", l.bridge.URL()+"/lights/"+l.ID, map[string]string{"name": name})
 	if err != nil {
 		return err
 	}
-	bodyBuf := bytes.NewBuffer(bodyBytes)
+	l.Name = name
+	return nil
+}
 
-	client := &http.Client{}
-	request, err := http.NewRequest("PUT", l.bridge.URL()+"/lights/"+l.id+"/name", bodyBuf)
+// On turns the light on.
+func (l *Light) On() error {
+	return l.OnContext(context.Background())
+}
+
+// OnContext is like On, the request is bound to the given context.
+func (l *Light) OnContext(ctx context.Context) error {
+	on := true
+	return l.SetStateContext(ctx, LightStateChange{On: &on})
+}
+
+// Off turns the light off.
+func (l *Light) Off() error {
+	return l.OffContext(context.Background())
+}
+
+// OffContext is like Off, the request is bound to the given context.
+func (l *Light) OffContext(ctx context.Context) error {
+	on := false
+	return l.SetStateContext(ctx, LightStateChange{On: &on})
+}
+
+// Toggle turns the light off when it is on, and on when it is off.
+// The State retrieved by GetAllLights or Refresh is used when it was retrieved moments ago,
+// or the state cached by the bridge when CacheTTL is set. Otherwise the light is refreshed first.
+// On success State.On holds the new on-state.
+func (l *Light) Toggle() error {
+	return l.ToggleContext(context.Background())
+}
+
+// ToggleContext is like Toggle, the requests are bound to the given context.
+func (l *Light) ToggleContext(ctx context.Context) error {
+	if l.fetched.IsZero() || time.Since(l.fetched) > lightStateMaxAge {
+		if lightsMap, fetched, ok := l.bridge.cachedLights(); ok && lightsMap[l.ID] != nil {
+			l.State = lightsMap[l.ID].State
+			l.fetched = fetched
+		} else {
+			err := l.RefreshContext(ctx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	on := !l.State.On
+	err := l.SetStateContext(ctx, LightStateChange{On: &on})
 	if err != nil {
 		return err
 	}
-	resp, err := client.Do(request)
+	l.State.On = on
+	return nil
+}
+
+// SetBrightness sets the brightness of the light.
+// The bridge accepts brightness values from 1 to 254, larger values are clamped to 254.
+// A brightness of 0 turns the light off.
+func (l *Light) SetBrightness(bri uint8) error {
+	return l.SetBrightnessContext(context.Background(), bri)
+}
+
+// SetBrightnessContext is like SetBrightness, the request is bound to the given context.
+func (l *Light) SetBrightnessContext(ctx context.Context, bri uint8) error {
+	if bri == 0 {
+		return l.OffContext(ctx)
+	}
+	if bri > 254 {
+		bri = 254
+	}
+	return l.SetStateContext(ctx, LightStateChange{Bri: &bri})
+}
+
+// AdjustBrightness changes the brightness of the light relative to its current brightness.
+// Delta is clamped to the range -254..254. A delta of 0 would not change anything and results in an error.
+func (l *Light) AdjustBrightness(delta int) error {
+	return l.AdjustBrightnessContext(context.Background(), delta)
+}
+
+// AdjustBrightnessContext is like AdjustBrightness, the request is bound to the given context.
+func (l *Light) AdjustBrightnessContext(ctx context.Context, delta int) error {
+	if delta == 0 {
+		return errors.New("brightness delta must not be 0")
+	}
+	if delta < -254 {
+		delta = -254
+	}
+	if delta > 254 {
+		delta = 254
+	}
+	return l.SetStateContext(ctx, LightStateChange{BriInc: &delta})
+}
+
+// maxFadeSegment is the longest transition FadeBrightness sends in a single state change
+const maxFadeSegment = 6000 * 100 * time.Millisecond
+
+// FadeBrightness changes the brightness of the light to target with a smooth transition taking the given duration.
+// Durations longer than the bridge accepts for a single transition (10 minutes) are split into multiple transitions,
+// FadeBrightness then returns when the last one was sent, or when the context is cancelled.
+// A light that is off is turned on and fades up from the lowest brightness. A target of 0 fades the light off.
+func (l *Light) FadeBrightness(ctx context.Context, target uint8, duration time.Duration) error {
+	if target > 254 {
+		target = 254
+	}
+	if l.fetched.IsZero() {
+		err := l.RefreshContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	start := int(l.State.Brightness)
+	if !l.State.On {
+		start = 1
+	}
+
+	segments := int((duration + maxFadeSegment - 1) / maxFadeSegment)
+	if segments < 1 {
+		segments = 1
+	}
+	segmentDuration := duration / time.Duration(segments)
+	for i := 1; i <= segments; i++ {
+		bri := uint8(start + (int(target)-start)*i/segments)
+		on := true
+		change := LightStateChange{On: &on, TransitionTime: TransitionTime(segmentDuration)}
+		if i == segments && target == 0 {
+			on = false
+		} else {
+			bri = max(bri, 1)
+			change.Bri = &bri
+		}
+		err := l.SetStateContext(ctx, change)
+		if err != nil {
+			return err
+		}
+		l.State.On = on
+		l.State.Brightness = bri
+		if i == segments {
+			break
+		}
+
+		timer := time.NewTimer(segmentDuration)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// Supported color temperature range in mired.
+const (
+	MinColorTemperature = 153 // 6500K
+	MaxColorTemperature = 500 // 2000K
+)
+
+// SetColorTemperature sets the color temperature of the light in mired.
+// The value is clamped to the range supported by the light, see CTRange.
+// Lights that are not capable of color temperature make the bridge return an error, which is returned as-is.
+func (l *Light) SetColorTemperature(mired uint16) error {
+	return l.SetColorTemperatureContext(context.Background(), mired)
+}
+
+// SetColorTemperatureContext is like SetColorTemperature, the request is bound to the given context.
+func (l *Light) SetColorTemperatureContext(ctx context.Context, mired uint16) error {
+	minMired, maxMired, ok := l.CTRange()
+	if !ok {
+		minMired, maxMired = MinColorTemperature, MaxColorTemperature
+	}
+	if mired < minMired {
+		mired = minMired
+	}
+	if mired > maxMired {
+		mired = maxMired
+	}
+	return l.SetStateContext(ctx, LightStateChange{CT: &mired})
+}
+
+// CTRange returns the range of color temperatures in mired supported by the light, as reported in its Capabilities.
+// The boolean is false when the light did not report its range, the lights then generally support
+// MinColorTemperature..MaxColorTemperature.
+func (l *Light) CTRange() (min, max uint16, ok bool) {
+	ct := l.Capabilities.Control.CT
+	if ct == nil || ct.Min == 0 || ct.Max < ct.Min {
+		return 0, 0, false
+	}
+	return ct.Min, ct.Max, true
+}
+
+// SetColorTemperatureKelvin sets the color temperature of the light in Kelvin.
+// The temperature is converted to mired (1000000/kelvin) and then set with SetColorTemperature.
+func (l *Light) SetColorTemperatureKelvin(kelvin uint) error {
+	return l.SetColorTemperatureKelvinContext(context.Background(), kelvin)
+}
+
+// SetColorTemperatureKelvinContext is like SetColorTemperatureKelvin, the request is bound to the given context.
+func (l *Light) SetColorTemperatureKelvinContext(ctx context.Context, kelvin uint) error {
+	if kelvin == 0 {
+		// infinitely warm, clamps to the warmest supported temperature
+		return l.SetColorTemperatureContext(ctx, MaxColorTemperature)
+	}
+	mired := 1000000 / kelvin
+	if mired > MaxColorTemperature {
+		mired = MaxColorTemperature
+	}
+	return l.SetColorTemperatureContext(ctx, uint16(mired))
+}
+
+// Alert effects that can be set with SetAlert.
+const (
+	AlertNone    = "none"    // stop performing an alert effect
+	AlertSelect  = "select"  // perform a single breathe cycle
+	AlertLSelect = "lselect" // perform breathe cycles for 15 seconds or until AlertNone is set
+)
+
+// SetAlert sets the alert effect of the light, which is a temporary change to the light's state.
+// Mode must be one of AlertNone, AlertSelect or AlertLSelect, otherwise an error is returned without contacting the bridge.
+func (l *Light) SetAlert(mode string) error {
+	return l.SetAlertContext(context.Background(), mode)
+}
+
+// SetAlertContext is like SetAlert, the request is bound to the given context.
+func (l *Light) SetAlertContext(ctx context.Context, mode string) error {
+	switch mode {
+	case AlertNone, AlertSelect, AlertLSelect:
+	default:
+		return fmt.Errorf("invalid alert mode %q, must be one of %q, %q or %q", mode, AlertNone, AlertSelect, AlertLSelect)
+	}
+	return l.SetStateContext(ctx, LightStateChange{Alert: &mode})
+}
+
+// Blink lets the light perform a single breathe cycle.
+func (l *Light) Blink() error {
+	return l.BlinkContext(context.Background())
+}
+
+// BlinkContext is like Blink, the request is bound to the given context.
+func (l *Light) BlinkContext(ctx context.Context) error {
+	return l.SetAlertContext(ctx, AlertSelect)
+}
+
+// BreatheFor15s lets the light perform breathe cycles for 15 seconds.
+func (l *Light) BreatheFor15s() error {
+	return l.BreatheFor15sContext(context.Background())
+}
+
+// BreatheFor15sContext is like BreatheFor15s, the request is bound to the given context.
+func (l *Light) BreatheFor15sContext(ctx context.Context) error {
+	return l.SetAlertContext(ctx, AlertLSelect)
+}
+
+// Effects that can be set with SetEffect.
+const (
+	EffectNone      = "none"      // no dynamic effect
+	EffectColorLoop = "colorloop" // cycle through all hues
+)
+
+// SetEffect sets the dynamic effect of the light.
+// Effect must be EffectNone or EffectColorLoop, otherwise an error is returned without contacting the bridge.
+// The colorloop effect cycles through all hues indefinitely, using the current brightness and saturation.
+// It is independent of brightness, so it can be combined with a prior SetBrightness.
+func (l *Light) SetEffect(effect string) error {
+	return l.SetEffectContext(context.Background(), effect)
+}
+
+// SetEffectContext is like SetEffect, the request is bound to the given context.
+func (l *Light) SetEffectContext(ctx context.Context, effect string) error {
+	switch effect {
+	case EffectNone, EffectColorLoop:
+	default:
+		return fmt.Errorf("invalid effect %q, must be %q or %q", effect, EffectNone, EffectColorLoop)
+	}
+	return l.SetStateContext(ctx, LightStateChange{Effect: &effect})
+}
+
+// StartColorLoop starts the colorloop effect on the light.
+func (l *Light) StartColorLoop() error {
+	return l.StartColorLoopContext(context.Background())
+}
+
+// StartColorLoopContext is like StartColorLoop, the request is bound to the given context.
+func (l *Light) StartColorLoopContext(ctx context.Context) error {
+	return l.SetEffectContext(ctx, EffectColorLoop)
+}
+
+// StopColorLoop stops the colorloop effect on the light.
+func (l *Light) StopColorLoop() error {
+	return l.StopColorLoopContext(context.Background())
+}
+
+// StopColorLoopContext is like StopColorLoop, the request is bound to the given context.
+func (l *Light) StopColorLoopContext(ctx context.Context) error {
+	return l.SetEffectContext(ctx, EffectNone)
+}
+
+// colorLoopStep is the interval between the hue changes sent by ColorLoopRange
+const colorLoopStep = time.Second
+
+// ColorLoopRange cycles the hue of the light back and forth between startHue and endHue, taking period for one
+// full cycle from startHue to endHue and back. When startHue is larger than endHue the loop passes through red,
+// wrapping around the end of the hue scale. Saturation and brightness are left unchanged.
+// Unlike the native colorloop effect, the loop is driven by this process: it sends a hue change every second
+// and only runs as long as the process does. ColorLoopRange blocks until the context is cancelled, and then
+// returns the context error, or returns early when a state change fails.
+func (l *Light) ColorLoopRange(ctx context.Context, startHue, endHue uint16, period time.Duration) error {
+	if period <= 0 {
+		return errors.New("color loop period must be positive")
+	}
+	steps := int(period / 2 / colorLoopStep)
+	if steps < 1 {
+		steps = 1
+	}
+	stepDuration := period / 2 / time.Duration(steps)
+	distance := int(endHue - startHue)
+
+	for i := 0; ; i++ {
+		// position on the way from startHue to endHue, counting back down in the second half of a cycle
+		position := i % (2 * steps)
+		if position > steps {
+			position = 2*steps - position
+		}
+		hue := startHue + uint16(distance*position/steps)
+		err := l.SetStateContext(ctx, LightStateChange{Hue: &hue, TransitionTime: TransitionTime(stepDuration)})
+		if err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(stepDuration)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Startup modes that can be set with SetStartupBehavior, defining the state of a light after a power cut.
+const (
+	StartupModeSafety    = "safety"    // turn on bright white
+	StartupModePowerFail = "powerfail" // restore the state from before the power cut
+	StartupModeCustom    = "custom"    // turn on with the custom settings, see SetCustomStartupBehavior
+)
+
+// StartupSettings holds the state of a light after a power cut, for StartupModeCustom.
+// Fields that are nil are omitted and are left unchanged on the light.
+type StartupSettings struct {
+	Bri *uint8  `json:"bri,omitempty"` // brightness, 1 to 254
+	CT  *uint16 `json:"ct,omitempty"`  // color temperature in mired
+	XY  *XY     `json:"xy,omitempty"`  // x and y coordinates of the color in CIE color space
+}
+
+// SetStartupBehavior sets what the light does after a power cut.
+// Mode must be one of StartupModeSafety, StartupModePowerFail or StartupModeCustom,
+// otherwise an error is returned without contacting the bridge. StartupModeCustom keeps the custom settings the light has.
+// Lights that don't support startup behavior make the bridge return an error, which is returned as-is.
+func (l *Light) SetStartupBehavior(mode string) error {
+	return l.SetStartupBehaviorContext(context.Background(), mode)
+}
+
+// SetStartupBehaviorContext is like SetStartupBehavior, the request is bound to the given context.
+func (l *Light) SetStartupBehaviorContext(ctx context.Context, mode string) error {
+	switch mode {
+	case StartupModeSafety, StartupModePowerFail, StartupModeCustom:
+	default:
+		return fmt.Errorf("invalid startup mode %q, must be one of %q, %q or %q", mode, StartupModeSafety, StartupModePowerFail, StartupModeCustom)
+	}
+	return l.setStartup(ctx, map[string]interface{}{"mode": mode})
+}
+
+// SetCustomStartupBehavior lets the light turn on with the given settings after a power cut, using StartupModeCustom.
+func (l *Light) SetCustomStartupBehavior(settings StartupSettings) error {
+	return l.SetCustomStartupBehaviorContext(context.Background(), settings)
+}
+
+// SetCustomStartupBehaviorContext is like SetCustomStartupBehavior, the request is bound to the given context.
+func (l *Light) SetCustomStartupBehaviorContext(ctx context.Context, settings StartupSettings) error {
+	return l.setStartup(ctx, map[string]interface{}{"mode": StartupModeCustom, "customsettings": settings})
+}
+
+// setStartup sends the startup configuration to the light
+func (l *Light) setStartup(ctx context.Context, startup map[string]interface{}) error {
+	_, err := l.bridge.sendAPIRequest(ctx, "PUT", l.bridge.URL()+"/lights/"+l.ID+"/config", map[string]interface{}{"startup": startup})
+	return err
+}
+
+// SetState applies the given state change to the light. Only the fields that are set in change are sent.
+// An error is returned when the bridge reports an error, e.g. when the light does not exist.
+// Relative changes outside their range are rejected without contacting the bridge, see LightStateChange.Validate.
+func (l *Light) SetState(change LightStateChange) error {
+	return l.SetStateContext(context.Background(), change)
+}
+
+// SetStateContext is like SetState, the request is bound to the given context.
+func (l *Light) SetStateContext(ctx context.Context, change LightStateChange) error {
+	err := change.Validate()
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	return nil
+	return l.bridge.sendStateChange(ctx, l.bridge.URL()+"/lights/"+l.ID+"/state", change)
+}
+
+// SetStateAndRefresh is like SetState, but refreshes the light afterwards and returns the resulting state.
+// The bridge only confirms which values were changed, the refreshed state holds the values it actually applied,
+// e.g. colors clamped to the gamut of the light. Use SetState to avoid the extra request when this is not needed.
+// During a transition the bridge reports the target values of the change.
+func (l *Light) SetStateAndRefresh(change LightStateChange) (*LightState, error) {
+	return l.SetStateAndRefreshContext(context.Background(), change)
+}
+
+// SetStateAndRefreshContext is like SetStateAndRefresh, the requests are bound to the given context.
+func (l *Light) SetStateAndRefreshContext(ctx context.Context, change LightStateChange) (*LightState, error) {
+	err := l.SetStateContext(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+	err = l.RefreshContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state := l.State
+	return &state, nil
+}
+
+// WaitForState refreshes the light every poll interval until pred reports true for its state,
+// e.g. to wait until a transition has turned the light off. The light is refreshed right away first.
+// When the context ends before that, the context error is returned. Errors of the refresh are returned as-is.
+func (l *Light) WaitForState(ctx context.Context, pred func(*LightState) bool, poll time.Duration) error {
+	if poll <= 0 {
+		return errors.New("poll interval must be positive")
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		err := l.RefreshContext(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if pred(&l.State) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
 
 // LightAttributes holds attributes of light, it includes the State and Name.
 type LightAttributes struct {
-	State     LightState `json:"State"`     // Details the state of the light, see the state table below for more details.
-	Type      string     `json:"Type"`      // A fixed name describing the type of light e.g. “Extended color light”.
-	Name      string     `json:"name"`      // (lenght 0-32) A unique, editable name given to the light.
-	ModelID   string     `json:"modelid"`   // (length 6) The hardware model of the light.
-	Swversion string     `json:"swversion"` // (length 8) An identifier for the software version running on the light.
+	State            LightState        `json:"state"`            // Details the state of the light, see the state table below for more details.
+	Type             string            `json:"type"`             // A fixed name describing the type of light e.g. “Extended color light”.
+	Name             string            `json:"name"`             // (lenght 0-32) A unique, editable name given to the light.
+	ModelID          string            `json:"modelid"`          // (length 6) The hardware model of the light.
+	Swversion        string            `json:"swversion"`        // (length 8) An identifier for the software version running on the light.
+	ManufacturerName string            `json:"manufacturername"` // The manufacturer of the light.
+	ProductName      string            `json:"productname"`      // The product name of the light, only reported by bridges with newer firmware.
+	Capabilities     LightCapabilities `json:"capabilities"`     // Capabilities of the light, only reported by bridges with newer firmware.
 	// Pointsymbol string     `json:"Pointsymbol"` // (object) This parameter is reserved for future functionality.
 }
 
+// LightCapabilities holds the capabilities of a light, as reported by bridges with newer firmware.
+type LightCapabilities struct {
+	Certified bool `json:"certified"` // whether the light is certified by Philips
+	Control   struct {
+		MinDimLevel    int    `json:"mindimlevel"`    // minimum dim level
+		MaxLumen       int    `json:"maxlumen"`       // maximum luminous flux in lumen
+		ColorGamutType string `json:"colorgamuttype"` // gamut of the light, “A”, “B” or “C”, empty for lights without color
+		CT             *struct {
+			Min uint16 `json:"min"` // warmest color temperature in mired
+			Max uint16 `json:"max"` // coolest color temperature in mired
+		} `json:"ct"` // supported color temperature range, nil for lights without color temperature
+	} `json:"control"`
+}
+
+// ColorMode is the color mode in which a light is working, see LightState.ColorMode.
+type ColorMode string
+
+// Color modes of a light.
+const (
+	ColorModeUnknown ColorMode = ""   // the light did not report a color mode, or one that is not known to this package
+	ColorModeHS      ColorMode = "hs" // hue and saturation
+	ColorModeXY      ColorMode = "xy" // x and y coordinates in CIE color space
+	ColorModeCT      ColorMode = "ct" // color temperature
+)
+
+// ParseColorMode returns the ColorMode for the given value as reported by the bridge, e.g. “hs”.
+// ColorModeUnknown is returned for values that are not known.
+func ParseColorMode(s string) ColorMode {
+	switch mode := ColorMode(s); mode {
+	case ColorModeHS, ColorModeXY, ColorModeCT:
+		return mode
+	default:
+		return ColorModeUnknown
+	}
+}
+
+// UnmarshalJSON decodes the color mode with ParseColorMode.
+func (m *ColorMode) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	*m = ParseColorMode(s)
+	return nil
+}
+
 type LightState struct {
-	On         bool   `json:"On"`  // On/Off state of the light. On=true, Off=false
-	Brightness uint8  `json:"Bri"` // Brightness of the light. This is a scale from the minimum brightness the light is capable of, 0, to the maximum capable brightness, 255. Note a brightness of 0 is not off.
-	Hue        uint16 `json:"Hue"` // Hue of the light. This is a wrapping value between 0 and 65535. Both 0 and 65535 are red, 25500 is green and 46920 is blue.
+	On         bool   `json:"on"`  // On/Off state of the light. On=true, Off=false
+	Brightness uint8  `json:"bri"` // Brightness of the light. This is a scale from the minimum brightness the light is capable of, 0, to the maximum capable brightness, 255. Note a brightness of 0 is not off.
+	Hue        uint16 `json:"hue"` // Hue of the light. This is a wrapping value between 0 and 65535. Both 0 and 65535 are red, 25500 is green and 46920 is blue.
 	Saturation uint8  `json:"sat"` // Saturation of the light. 255 is the most saturated (colored) and 0 is the least saturated (white).
 
-	// xy ?? // 2..2 of float 4	The x and y coordinates of a color in CIE color space.
+	XY XY `json:"xy"` // The x and y coordinates of a color in CIE color space.
 	// The first entry is the x coordinate and the second entry is the y coordinate. Both x and y are between 0 and 1.
 
 	CT uint16 `json:"ct"` // The Mired Color temperature of the light. 2012 connected lights are capable of 153 (6500K) to 500 (2000K).
@@ -80,45 +649,211 @@ type LightState struct {
 	Alert string `json:"alert"` // The alert effect, which is a temporary change to the bulb’s state. This can take one of the following values:
 	// “none” – The light is not performing an alert effect.
 	// “select” – The light is performing one breathe cycle.
-	// “lselect” – The light is performing breathe cycles for 30 seconds or until an "alert": "none" command is received.
+	// “lselect” – The light is performing breathe cycles for 15 seconds or until an "alert": "none" command is received.
 	// Note that in version 1.0 this contains the last alert sent to the light and not its current state. This will be changed to contain the current state in an upcoming patch.
 
 	Effect string `json:"effect"` // The dynamic effect of the light, can either be “none” or “colorloop”.
 
 	// If set to colorloop, the light will cycle through all hues using the current brightness and saturation settings.
-	ColorMode string `json:"colormode"` // (length 2) Indicates the color mode in which the light is working, this is the last command type it received. Values are “hs” for Hue and Saturation, “xy” for XY and “ct” for Color Temperature. This parameter is only present when the light supports at least one of the values.
-	Reachable bool   `json:"reachable"` // Indicates if a light can be reached by the bridge. Currently always returns true, functionality will be added in a future patch.
+	ColorMode ColorMode `json:"colormode"` // (length 2) Indicates the color mode in which the light is working, this is the last command type it received. Values are ColorModeHS for Hue and Saturation, ColorModeXY for XY and ColorModeCT for Color Temperature. This parameter is only present when the light supports at least one of the values, ColorModeUnknown otherwise.
+	Reachable bool      `json:"reachable"` // Indicates if a light can be reached by the bridge, false when it is e.g. powered off at the switch.
 }
 
 // Lights returns all lights known by the bridge.
 func (b *Bridge) Lights() ([]Light, error) {
-	resp, err := http.Get(b.URL() + "/lights")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return b.LightsContext(context.Background())
+}
 
+// LightsContext is like Lights, the request is bound to the given context.
+func (b *Bridge) LightsContext(ctx context.Context) ([]Light, error) {
 	lightsMap := map[string]interface{}{} // we use interface{} to discard the value on each key
-	err = json.NewDecoder(resp.Body).Decode(&lightsMap)
+	err := b.getJSON(ctx, b.URL()+"/lights", &lightsMap)
 	if err != nil {
 		return nil, err
 	}
 	lights := make([]Light, 0, len(lightsMap))
 	for lightID, _ := range lightsMap {
-		lights = append(lights, Light{b, lightID})
+		lights = append(lights, Light{bridge: b, ID: lightID})
 	}
 	return lights, nil
 }
 
+// GetAllLights returns all lights known by the bridge, including their name and current state.
+// The lights are sorted by their numeric ID. When the bridge has no lights, an empty slice is returned.
+// When CacheTTL is set, lights retrieved less than CacheTTL ago are returned without contacting the bridge.
+func (b *Bridge) GetAllLights() ([]*Light, error) {
+	return b.GetAllLightsContext(context.Background())
+}
+
+// GetAllLightsContext is like GetAllLights, the request is bound to the given context.
+func (b *Bridge) GetAllLightsContext(ctx context.Context) ([]*Light, error) {
+	lightsMap, fetched, ok := b.cachedLights()
+	if !ok {
+		lightsMap = map[string]*LightAttributes{}
+		err := b.getJSON(ctx, b.URL()+"/lights", &lightsMap)
+		if err != nil {
+			return nil, err
+		}
+		fetched = time.Now()
+		b.cacheLights(lightsMap, fetched)
+	}
+	return b.newLights(lightsMap, fetched), nil
+}
+
+// GetReachableLights returns the lights that the bridge can reach, like GetAllLights does for all lights.
+// Lights that are powered off at the switch are not reachable, sending them commands has no effect.
+func (b *Bridge) GetReachableLights() ([]*Light, error) {
+	return b.GetReachableLightsContext(context.Background())
+}
+
+// GetReachableLightsContext is like GetReachableLights, the request is bound to the given context.
+func (b *Bridge) GetReachableLightsContext(ctx context.Context) ([]*Light, error) {
+	return b.getLightsByReachability(ctx, true)
+}
+
+// GetUnreachableLights returns the lights that the bridge can't reach, e.g. because they are powered off at the switch.
+func (b *Bridge) GetUnreachableLights() ([]*Light, error) {
+	return b.GetUnreachableLightsContext(context.Background())
+}
+
+// GetUnreachableLightsContext is like GetUnreachableLights, the request is bound to the given context.
+func (b *Bridge) GetUnreachableLightsContext(ctx context.Context) ([]*Light, error) {
+	return b.getLightsByReachability(ctx, false)
+}
+
+// getLightsByReachability returns the lights whose State.Reachable equals reachable, sorted by their numeric ID.
+func (b *Bridge) getLightsByReachability(ctx context.Context, reachable bool) ([]*Light, error) {
+	lights, err := b.GetAllLightsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]*Light, 0, len(lights))
+	for _, light := range lights {
+		if light.State.Reachable == reachable {
+			filtered = append(filtered, light)
+		}
+	}
+	return filtered, nil
+}
+
+// newLights creates the lights from the attributes by light id, as retrieved at the given time.
+// The lights are sorted by their numeric ID.
+func (b *Bridge) newLights(lightsMap map[string]*LightAttributes, fetched time.Time) []*Light {
+	lights := make([]*Light, 0, len(lightsMap))
+	for lightID, attributes := range lightsMap {
+		light := &Light{bridge: b, ID: lightID}
+		light.setAttributes(attributes, fetched)
+		lights = append(lights, light)
+	}
+	sort.Slice(lights, func(i, j int) bool {
+		return lessNumericID(lights[i].ID, lights[j].ID)
+	})
+	return lights
+}
+
+// GetLightByName returns the first light whose name equals the given name.
+// ErrLightNotFound is returned when no light matches.
+func (b *Bridge) GetLightByName(name string) (*Light, error) {
+	return b.GetLightByNameContext(context.Background(), name)
+}
+
+// GetLightByNameContext is like GetLightByName, the request is bound to the given context.
+func (b *Bridge) GetLightByNameContext(ctx context.Context, name string) (*Light, error) {
+	lights, err := b.GetAllLightsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, light := range lights {
+		if lightNameMatches(light.Name, name) {
+			return light, nil
+		}
+	}
+	return nil, ErrLightNotFound
+}
+
+// lightNameMatches reports whether the name of a light matches the name that is looked up.
+// Names are compared case-sensitive.
+func lightNameMatches(lightName string, name string) bool {
+	return lightName == name
+}
+
+// DeleteLight removes the light with the given id from the bridge.
+// The bridge rejects the deletion with an *APIError when the light is in use, e.g. by a group or scene.
+func (b *Bridge) DeleteLight(id string) error {
+	return b.DeleteLightContext(context.Background(), id)
+}
+
+// DeleteLightContext is like DeleteLight, the request is bound to the given context.
+func (b *Bridge) DeleteLightContext(ctx context.Context, id string) error {
+	_, err := b.sendAPIRequest(ctx, "DELETE", b.URL()+"/lights/"+id, nil)
+	return err
+}
+
 // Search lets the bridge start a new search for lights.
+//
+// Deprecated: use SearchForNewLights or SearchForNewLightsContext, which also report errors returned by the bridge.
+func (b *Bridge) Search() error {
+	return b.SearchForNewLights()
+}
+
+// SearchForNewLights lets the bridge start a new search for lights.
 // The bridge will search for 1 minute and will add a maximum of 15 new lights.
 // To add further lights, the command needs to be sent again after the search has completed.
 // If a search is already active, it will be aborted and a new search will start.
-func (b *Bridge) Search() error {
-	resp, err := http.Post(b.URL()+"/lights", "", nil)
+// Use GetNewLights to retrieve the lights that were found.
+func (b *Bridge) SearchForNewLights() error {
+	return b.SearchForNewLightsContext(context.Background())
+}
+
+// SearchForNewLightsContext is like SearchForNewLights, the request is bound to the given context.
+func (b *Bridge) SearchForNewLightsContext(ctx context.Context) error {
+	_, err := b.sendAPIRequest(ctx, "POST", b.URL()+"/lights", nil)
+	return err
+}
+
+// NewLights holds the lights found by the last search for new lights.
+type NewLights struct {
+	Lights     map[string]string // names of the lights that were found, by light id
+	ScanActive bool              // whether a search is currently active
+	LastScan   Time              // time at which the last search was started, zero when no search was done and while ScanActive
+}
+
+// GetNewLights returns the lights found by the last search started with SearchForNewLights.
+func (b *Bridge) GetNewLights() (*NewLights, error) {
+	return b.GetNewLightsContext(context.Background())
+}
+
+// GetNewLightsContext is like GetNewLights, the request is bound to the given context.
+func (b *Bridge) GetNewLightsContext(ctx context.Context) (*NewLights, error) {
+	newLightsMap := map[string]json.RawMessage{}
+	err := b.getJSON(ctx, b.URL()+"/lights/new", &newLightsMap)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
-	return nil
+
+	newLights := &NewLights{Lights: make(map[string]string, len(newLightsMap))}
+	for key, value := range newLightsMap {
+		if key != "lastscan" {
+			light := struct {
+				Name string `json:"name"`
+			}{}
+			err = json.Unmarshal(value, &light)
+			if err != nil {
+				return nil, err
+			}
+			newLights.Lights[key] = light.Name
+			continue
+		}
+
+		// lastscan is "active" while searching, "none" when no search was done, or the time of the last search
+		if string(value) == `"active"` {
+			newLights.ScanActive = true
+			continue
+		}
+		err = json.Unmarshal(value, &newLights.LastScan)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newLights, nil
 }
@@ -0,0 +1,25 @@
+package hue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetEffectRejectsUnknownEffect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s, an unknown effect must be rejected before contacting the bridge", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	b := NewBridge(strings.TrimPrefix(server.URL, "http://"))
+	b.Username = "testuser"
+	light := &Light{bridge: b, ID: "1"}
+	for _, effect := range []string{"", "rainbow", "ColorLoop", "none "} {
+		err := light.SetEffect(effect)
+		if err == nil {
+			t.Errorf("SetEffect(%q) returned no error, want an error for the unknown effect", effect)
+		}
+	}
+}
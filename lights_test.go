@@ -0,0 +1,145 @@
+package hue_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GeertJohan/go.hue"
+	"github.com/GeertJohan/go.hue/huetest"
+)
+
+// newLampServer starts a fake bridge serving a single color light with id 1, named Lamp.
+func newLampServer() *huetest.Server {
+	srv := huetest.NewServer()
+	srv.SetLight("1", hue.LightAttributes{
+		Name:    "Lamp",
+		Type:    hue.LightTypeExtendedColor,
+		ModelID: "LCT015",
+		State:   hue.LightState{On: true, Brightness: 100, Reachable: true},
+	})
+	return srv
+}
+
+// lamp returns the light named Lamp of the fake bridge.
+func lamp(t *testing.T, srv *huetest.Server) *hue.Light {
+	t.Helper()
+	l, err := srv.Bridge().GetLightByName("Lamp")
+	if err != nil {
+		t.Fatalf("GetLightByName: %v", err)
+	}
+	return l
+}
+
+func TestSetBrightness(t *testing.T) {
+	tests := []struct {
+		bri  uint8
+		body string
+	}{
+		{0, `{"on":false}`},
+		{1, `{"bri":1}`},
+		{128, `{"bri":128}`},
+		{254, `{"bri":254}`},
+		{255, `{"bri":254}`},
+	}
+	for _, test := range tests {
+		srv := newLampServer()
+		defer srv.Close()
+		err := lamp(t, srv).SetBrightness(test.bri)
+		if err != nil {
+			t.Fatalf("SetBrightness(%d): %v", test.bri, err)
+		}
+		commands := srv.Commands()
+		if len(commands) != 1 {
+			t.Fatalf("SetBrightness(%d) sent %d commands, want 1", test.bri, len(commands))
+		}
+		if commands[0].Method != "PUT" || commands[0].Path != "/lights/1/state" {
+			t.Errorf("SetBrightness(%d) sent %s %s, want PUT /lights/1/state", test.bri, commands[0].Method, commands[0].Path)
+		}
+		if string(commands[0].Body) != test.body {
+			t.Errorf("SetBrightness(%d) sent body %s, want %s", test.bri, commands[0].Body, test.body)
+		}
+	}
+}
+
+func TestDeleteLight(t *testing.T) {
+	srv := newLampServer()
+	defer srv.Close()
+	srv.SetLight("3", hue.LightAttributes{Name: "Desk"})
+
+	err := srv.Bridge().DeleteLight("3")
+	if err != nil {
+		t.Fatalf("DeleteLight: %v", err)
+	}
+	commands := srv.Commands()
+	if len(commands) != 1 {
+		t.Fatalf("DeleteLight sent %d commands, want 1", len(commands))
+	}
+	if commands[0].Method != "DELETE" || commands[0].Path != "/lights/3" {
+		t.Errorf("DeleteLight sent %s %s, want DELETE /lights/3", commands[0].Method, commands[0].Path)
+	}
+	if _, ok := srv.Light("3"); ok {
+		t.Error("light 3 still exists after DeleteLight")
+	}
+}
+
+func TestDeleteLightInUse(t *testing.T) {
+	srv := newLampServer()
+	defer srv.Close()
+	srv.SetLight("3", hue.LightAttributes{Name: "Desk"})
+	srv.SetError("DELETE", "/lights/3", hue.APIError{Type: 307, Address: "/lights/3", Description: "Cannot delete, light is in use by group 2"})
+
+	err := srv.Bridge().DeleteLight("3")
+	var apiError *hue.APIError
+	if !errors.As(err, &apiError) {
+		t.Fatalf("DeleteLight returned %v, want an *APIError", err)
+	}
+	if apiError.Type != 307 || apiError.Address != "/lights/3" || apiError.Description != "Cannot delete, light is in use by group 2" {
+		t.Errorf("DeleteLight returned %+v, want the error reported by the bridge", apiError)
+	}
+	if _, ok := srv.Light("3"); !ok {
+		t.Error("light 3 is gone after a failed DeleteLight")
+	}
+}
+
+func TestSetBrightnessContextCancelled(t *testing.T) {
+	srv := newLampServer()
+	defer srv.Close()
+	l := lamp(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, bri := range []uint8{0, 100} {
+		err := l.SetBrightnessContext(ctx, bri)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("SetBrightnessContext(%d) with a cancelled context returned %v, want context.Canceled", bri, err)
+		}
+	}
+	if commands := srv.Commands(); len(commands) != 0 {
+		t.Errorf("SetBrightnessContext with a cancelled context sent %d commands, want none", len(commands))
+	}
+}
+
+func TestRenameLightCountsCharacters(t *testing.T) {
+	srv := newLampServer()
+	defer srv.Close()
+	l := lamp(t, srv)
+
+	// 32 characters, but more than 32 bytes
+	name := strings.Repeat("ä", 32)
+	err := l.Rename(name)
+	if err != nil {
+		t.Fatalf("Rename with a name of 32 characters: %v", err)
+	}
+	if attributes, _ := srv.Light("1"); attributes.Name != name {
+		t.Errorf("light is named %q after Rename, want %q", attributes.Name, name)
+	}
+	err = l.Rename(name + "ä")
+	if err == nil {
+		t.Error("Rename with a name of 33 characters returned no error")
+	}
+	if commands := srv.CommandsTo("/lights/1"); len(commands) != 1 {
+		t.Errorf("Rename sent %d commands to /lights/1, want 1", len(commands))
+	}
+}
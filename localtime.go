@@ -0,0 +1,187 @@
+package hue
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepeatForever is used as LocalTime.Repeat for a timer that repeats indefinitely.
+const RepeatForever = -1
+
+// LocalTime is a structured form of the local time at which a schedule expires, see ScheduleSpec.When.
+// It is one of three kinds:
+//   - a timer, when Timer is set: the schedule expires Timer after it was created, repeated Repeat times;
+//   - recurring, when Weekdays is set: the schedule expires at TimeOfDay on each of the Weekdays;
+//   - absolute otherwise: the schedule expires at the wall clock of Time, in the timezone of the bridge.
+//
+// For all kinds, Random adds a random delay between 0 and Random to each expiry.
+type LocalTime struct {
+	Time      time.Time      // absolute time, only the wall clock is used
+	Weekdays  []time.Weekday // days on which a recurring schedule expires
+	TimeOfDay time.Duration  // time since midnight at which a recurring schedule expires
+	Timer     time.Duration  // duration after which a timer expires
+	Repeat    int            // number of times a timer runs after the first time, or RepeatForever
+	Random    time.Duration  // maximum random delay added to each expiry
+}
+
+// AbsoluteTime returns a LocalTime that expires once, at the wall clock of t.
+func AbsoluteTime(t time.Time) LocalTime {
+	return LocalTime{Time: t}
+}
+
+// RecurringTime returns a LocalTime that expires at timeOfDay (the time since midnight) on each of the weekdays.
+func RecurringTime(timeOfDay time.Duration, weekdays ...time.Weekday) LocalTime {
+	return LocalTime{Weekdays: weekdays, TimeOfDay: timeOfDay}
+}
+
+// TimerTime returns a LocalTime that expires d after the schedule was created.
+func TimerTime(d time.Duration) LocalTime {
+	return LocalTime{Timer: d}
+}
+
+// weekdayBits holds the bit of each weekday in the weekday mask of recurring times, Monday is the most significant bit.
+var weekdayBits = map[time.Weekday]int{
+	time.Monday:    64,
+	time.Tuesday:   32,
+	time.Wednesday: 16,
+	time.Thursday:  8,
+	time.Friday:    4,
+	time.Saturday:  2,
+	time.Sunday:    1,
+}
+
+// String formats the local time as expected by the bridge, e.g. “W124/T18:00:00” or “PT00:10:00”.
+func (lt LocalTime) String() string {
+	var s string
+	switch {
+	case lt.Timer > 0:
+		s = "PT" + formatClock(lt.Timer)
+		switch {
+		case lt.Repeat == RepeatForever:
+			s = "R/" + s
+		case lt.Repeat > 0:
+			s = fmt.Sprintf("R%02d/%s", lt.Repeat, s)
+		}
+	case len(lt.Weekdays) > 0:
+		mask := 0
+		for _, weekday := range lt.Weekdays {
+			mask |= weekdayBits[weekday]
+		}
+		s = fmt.Sprintf("W%d/T%s", mask, formatClock(lt.TimeOfDay))
+	default:
+		s = lt.Time.Format(timeLayout)
+	}
+	if lt.Random > 0 {
+		s += "A" + formatClock(lt.Random)
+	}
+	return s
+}
+
+// validate checks that the local time can be represented in the format expected by the bridge.
+func (lt LocalTime) validate() error {
+	if lt.Timer == 0 && len(lt.Weekdays) == 0 && lt.Time.IsZero() {
+		return errors.New("local time has no absolute time, timer or weekdays set")
+	}
+	if lt.Timer > 0 && (lt.Repeat < RepeatForever || lt.Repeat > 99) {
+		return fmt.Errorf("timer repeat %d out of range, must be between 0 and 99 or RepeatForever", lt.Repeat)
+	}
+	for _, d := range []time.Duration{lt.Timer, lt.TimeOfDay, lt.Random} {
+		if d < 0 || d >= 24*time.Hour {
+			return fmt.Errorf("duration %v out of range, must be less than 24 hours", d)
+		}
+	}
+	return nil
+}
+
+// ParseLocalTime parses the local time of a schedule as reported by the bridge, e.g. “W124/T18:00:00”.
+func ParseLocalTime(s string) (LocalTime, error) {
+	lt := LocalTime{}
+	invalid := fmt.Errorf("invalid local time %q", s)
+
+	if i := strings.LastIndex(s, "A"); i >= 0 {
+		random, err := parseClock(s[i+1:])
+		if err != nil {
+			return LocalTime{}, invalid
+		}
+		lt.Random = random
+		s = s[:i]
+	}
+
+	switch {
+	case strings.HasPrefix(s, "R"):
+		repeat, timer, ok := strings.Cut(strings.TrimPrefix(s, "R"), "/")
+		if !ok || !strings.HasPrefix(timer, "PT") {
+			return LocalTime{}, invalid
+		}
+		lt.Repeat = RepeatForever
+		if len(repeat) > 0 {
+			n, err := strconv.Atoi(repeat)
+			if err != nil || n < 0 {
+				return LocalTime{}, invalid
+			}
+			lt.Repeat = n
+		}
+		d, err := parseClock(strings.TrimPrefix(timer, "PT"))
+		if err != nil || d == 0 {
+			return LocalTime{}, invalid
+		}
+		lt.Timer = d
+	case strings.HasPrefix(s, "PT"):
+		d, err := parseClock(strings.TrimPrefix(s, "PT"))
+		if err != nil || d == 0 {
+			return LocalTime{}, invalid
+		}
+		lt.Timer = d
+	case strings.HasPrefix(s, "W"):
+		mask, clock, ok := strings.Cut(strings.TrimPrefix(s, "W"), "/T")
+		if !ok {
+			return LocalTime{}, invalid
+		}
+		n, err := strconv.Atoi(mask)
+		if err != nil || n < 1 || n > 127 {
+			return LocalTime{}, invalid
+		}
+		for _, weekday := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+			if n&weekdayBits[weekday] != 0 {
+				lt.Weekdays = append(lt.Weekdays, weekday)
+			}
+		}
+		lt.TimeOfDay, err = parseClock(clock)
+		if err != nil {
+			return LocalTime{}, invalid
+		}
+	default:
+		t, err := time.Parse(timeLayout, s)
+		if err != nil {
+			return LocalTime{}, invalid
+		}
+		lt.Time = t
+	}
+	return lt, nil
+}
+
+// formatClock formats d as hh:mm:ss.
+func formatClock(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// parseClock parses hh:mm:ss into a duration.
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, errors.New("clock must have the form hh:mm:ss")
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 || (i > 0 && n > 59) {
+			return 0, errors.New("clock must have the form hh:mm:ss")
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
@@ -0,0 +1,48 @@
+package hue
+
+import (
+	"errors"
+	"strings"
+)
+
+// Logger receives log messages about the requests sent to the bridge, see Bridge.Logger.
+// It is satisfied by *testing.T, use LoggerFunc to adapt a Printf-like function, e.g. LoggerFunc(log.Printf).
+type Logger interface {
+	Logf(format string, args ...interface{})
+}
+
+// LoggerFunc adapts a Printf-like function to a Logger.
+type LoggerFunc func(format string, args ...interface{})
+
+// Logf calls f.
+func (f LoggerFunc) Logf(format string, args ...interface{}) {
+	f(format, args...)
+}
+
+// logf logs a message when the bridge has a Logger set.
+func (b *Bridge) logf(format string, args ...interface{}) {
+	if b.Logger != nil {
+		b.Logger.Logf(format, args...)
+	}
+}
+
+// logResult logs the outcome of a request after the response was decoded: the errors reported by the bridge, if any.
+func (b *Bridge) logResult(method string, url string, err error) {
+	if b.Logger == nil || err == nil {
+		return
+	}
+	var apiError *APIError
+	var apiErrors APIErrors
+	if errors.As(err, &apiError) || errors.As(err, &apiErrors) {
+		b.logf("hue: %s %s: bridge reported error: %v", method, b.redact(url), err)
+	}
+}
+
+// redact returns s with the Username of the bridge replaced by ***, so it can be logged safely.
+func (b *Bridge) redact(s string) string {
+	_, username := b.credentials()
+	if len(username) == 0 {
+		return s
+	}
+	return strings.ReplaceAll(s, username, "***")
+}
@@ -0,0 +1,35 @@
+package hue
+
+import (
+	"net/http"
+)
+
+// Doer sends a http request and returns its response. It is satisfied by *http.Client.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to a Doer.
+type DoerFunc func(request *http.Request) (*http.Response, error)
+
+// Do calls f.
+func (f DoerFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// Middleware wraps the Doer that sends the requests to the bridge, see Bridge.Middleware.
+// A middleware can inspect or modify the request before calling next, and the response or error after it,
+// e.g. to record metrics or tracing spans. Note that the url of v1 api requests contains the Username.
+type Middleware func(next Doer) Doer
+
+// do sends the request with client, through the middleware of the bridge.
+func (b *Bridge) do(client *http.Client, request *http.Request) (*http.Response, error) {
+	if len(b.Middleware) == 0 {
+		return client.Do(request)
+	}
+	var doer Doer = client
+	for i := len(b.Middleware) - 1; i >= 0; i-- {
+		doer = b.Middleware[i](doer)
+	}
+	return doer.Do(request)
+}
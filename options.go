@@ -0,0 +1,45 @@
+package hue
+
+import (
+	"net/http"
+	"time"
+)
+
+// BridgeOption configures a Bridge created with NewBridge.
+type BridgeOption func(*Bridge)
+
+// WithUsername sets the Username of the bridge.
+func WithUsername(username string) BridgeOption {
+	return func(b *Bridge) {
+		b.Username = username
+	}
+}
+
+// WithHTTPClient sets the HTTPClient of the bridge.
+func WithHTTPClient(client *http.Client) BridgeOption {
+	return func(b *Bridge) {
+		b.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the Timeout for requests to the bridge, for both the v1 and the v2 api.
+// It leaves the HTTPClient unset, so the v2 api keeps verifying the bridge certificate with BridgeTLSConfig.
+func WithTimeout(timeout time.Duration) BridgeOption {
+	return func(b *Bridge) {
+		b.Timeout = timeout
+	}
+}
+
+// WithMiddleware appends the given middleware to the Middleware of the bridge.
+func WithMiddleware(middleware ...Middleware) BridgeOption {
+	return func(b *Bridge) {
+		b.Middleware = append(b.Middleware, middleware...)
+	}
+}
+
+// WithLogger sets the Logger of the bridge.
+func WithLogger(logger Logger) BridgeOption {
+	return func(b *Bridge) {
+		b.Logger = logger
+	}
+}
@@ -0,0 +1,36 @@
+package hue_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GeertJohan/go.hue"
+)
+
+func TestWithTimeoutV2(t *testing.T) {
+	delay := make(chan time.Duration, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(<-delay)
+		io.WriteString(w, `{"errors":[],"data":[]}`)
+	}))
+	defer server.Close()
+
+	b := hue.NewBridge(strings.TrimPrefix(server.URL, "https://"), hue.WithUsername(testUsername), hue.WithTimeout(100*time.Millisecond))
+	b.InsecureSkipVerify = true
+
+	delay <- 0
+	_, err := b.GetLightsV2()
+	if err != nil {
+		t.Fatalf("GetLightsV2 with WithTimeout and InsecureSkipVerify: %v", err)
+	}
+
+	delay <- 500 * time.Millisecond
+	_, err = b.GetLightsV2()
+	if err == nil {
+		t.Error("GetLightsV2 to a bridge responding after the timeout returned no error")
+	}
+}
@@ -0,0 +1,63 @@
+package hue
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultCommandInterval is the minimum interval between light commands the bridge can handle reliably (about 10 per second).
+const DefaultCommandInterval = 100 * time.Millisecond
+
+// CommandQueue serializes state changes sent to the bridge, and spaces them by a minimum interval.
+// The bridge drops commands when too many are sent at once, a CommandQueue prevents this.
+// A CommandQueue is safe for concurrent use.
+type CommandQueue struct {
+	minInterval time.Duration
+	slot        chan struct{} // holds a token while a command is being sent
+	last        time.Time     // time at which the last command was sent, guarded by slot
+}
+
+// NewCommandQueue creates a new CommandQueue that waits at least minInterval between commands.
+func NewCommandQueue(minInterval time.Duration) *CommandQueue {
+	return &CommandQueue{
+		minInterval: minInterval,
+		slot:        make(chan struct{}, 1),
+	}
+}
+
+// do waits until the queue is free and the minimum interval has passed, and then calls send.
+// When the context is cancelled while waiting, ctx.Err() is returned and send is not called.
+func (q *CommandQueue) do(ctx context.Context, send func() error) error {
+	select {
+	case q.slot <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-q.slot }()
+
+	if wait := q.minInterval - time.Since(q.last); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	err := send()
+	q.last = time.Now()
+	return err
+}
+
+// sendStateChange sends a state change to the given url, through the command queue when the bridge has one.
+func (b *Bridge) sendStateChange(ctx context.Context, url string, change interface{}) error {
+	send := func() error {
+		_, err := b.sendAPIRequest(ctx, "PUT", url, change)
+		return err
+	}
+	if b.CommandQueue == nil {
+		return send()
+	}
+	return b.CommandQueue.do(ctx, send)
+}
@@ -0,0 +1,48 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy defines how requests are retried when the bridge is too busy to handle them.
+// Requests are retried when the bridge responds with http status 429 (too many requests) or with an internal error (type 901).
+// Only GET and PUT requests are retried, as these can safely be repeated.
+// The zero value disables retries.
+type RetryPolicy struct {
+	MaxAttempts int           // maximum number of attempts, including the first attempt. Values below 2 disable retries.
+	BaseDelay   time.Duration // delay before the first retry, the delay is doubled for each next retry
+}
+
+// retry calls attempt until it succeeds, returns an error that is not retryable, or the retry policy is exhausted.
+// Between attempts it waits with exponential backoff, when the context is cancelled during the wait ctx.Err() is returned.
+func (b *Bridge) retry(ctx context.Context, method string, attempt func() error) error {
+	err := attempt()
+	if method != "GET" && method != "PUT" {
+		return err
+	}
+	delay := b.RetryPolicy.BaseDelay
+	for i := 1; i < b.RetryPolicy.MaxAttempts && isRetryable(err); i++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+		err = attempt()
+	}
+	return err
+}
+
+// isRetryable reports whether err indicates the bridge was too busy to handle a request.
+func isRetryable(err error) bool {
+	var transportError *TransportError
+	if errors.As(err, &transportError) {
+		return transportError.StatusCode == http.StatusTooManyRequests
+	}
+	return isAPIErrorType(err, ErrorTypeInternalError)
+}
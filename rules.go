@@ -0,0 +1,109 @@
+package hue
+
+import (
+	"context"
+	"sort"
+)
+
+// Operators that can be used in a RuleCondition.
+const (
+	RuleOperatorEquals      = "eq"         // value equals the condition value
+	RuleOperatorGreaterThan = "gt"         // value is greater than the condition value
+	RuleOperatorLessThan    = "lt"         // value is less than the condition value
+	RuleOperatorChanged     = "dx"         // value has changed
+	RuleOperatorChangedAgo  = "ddx"        // value has changed a given amount of time ago
+	RuleOperatorStable      = "stable"     // value has not changed for a given amount of time
+	RuleOperatorNotStable   = "not stable" // value has changed within a given amount of time
+	RuleOperatorIn          = "in"         // current time is in the given interval
+	RuleOperatorNotIn       = "not in"     // current time is not in the given interval
+)
+
+// RuleCondition is a condition that must hold for a rule to trigger.
+type RuleCondition struct {
+	Address  string `json:"address"`         // address of the attribute to check, e.g. “/sensors/2/state/presence”
+	Operator string `json:"operator"`        // operator to apply, see the RuleOperator constants
+	Value    string `json:"value,omitempty"` // value to compare with, not used for all operators
+}
+
+// Rule holds conditions and actions that are evaluated and executed by the bridge.
+// When all conditions hold, the bridge executes the actions.
+type Rule struct {
+	ID             string          `json:"-"`              // id of the rule
+	Name           string          `json:"name"`           // name of the rule
+	Owner          string          `json:"owner"`          // whitelist user that created the rule
+	Created        *Time           `json:"created"`        // time at which the rule was created
+	LastTriggered  *Time           `json:"lasttriggered"`  // time at which the rule was last triggered
+	TimesTriggered int             `json:"timestriggered"` // number of times the rule was triggered
+	Status         string          `json:"status"`         // “enabled” or “disabled”
+	Conditions     []RuleCondition `json:"conditions"`     // conditions that must all hold for the rule to trigger
+	Actions        []Command       `json:"actions"`        // commands executed when the rule is triggered
+}
+
+// RuleSpec holds the values for a rule that is created with CreateRule.
+type RuleSpec struct {
+	Name       string          `json:"name,omitempty"` // length 0..32. Name of the rule.
+	Conditions []RuleCondition `json:"conditions"`     // conditions that must all hold for the rule to trigger
+	Actions    []Command       `json:"actions"`        // commands executed when the rule is triggered
+}
+
+// GetAllRules returns all rules stored on the bridge, sorted by their numeric ID.
+func (b *Bridge) GetAllRules() ([]*Rule, error) {
+	return b.GetAllRulesContext(context.Background())
+}
+
+// GetAllRulesContext is like GetAllRules, the request is bound to the given context.
+func (b *Bridge) GetAllRulesContext(ctx context.Context) ([]*Rule, error) {
+	rulesMap := map[string]*Rule{}
+	err := b.getJSON(ctx, b.URL()+"/rules", &rulesMap)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*Rule, 0, len(rulesMap))
+	for ruleID, rule := range rulesMap {
+		rule.ID = ruleID
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return lessNumericID(rules[i].ID, rules[j].ID)
+	})
+	return rules, nil
+}
+
+// CreateRule creates a new rule on the bridge.
+// The returned rule has the ID that was assigned by the bridge.
+// When the bridge rejects the rule, the *APIError (or APIErrors when the bridge reports multiple problems)
+// indicates the offending condition or action in its Address.
+func (b *Bridge) CreateRule(spec RuleSpec) (*Rule, error) {
+	return b.CreateRuleContext(context.Background(), spec)
+}
+
+// CreateRuleContext is like CreateRule, the request is bound to the given context.
+func (b *Bridge) CreateRuleContext(ctx context.Context, spec RuleSpec) (*Rule, error) {
+	apiResponseSlice, err := b.sendAPIRequest(ctx, "POST", b.URL()+"/rules", spec)
+	if err != nil {
+		return nil, err
+	}
+	ruleID, err := createdID(apiResponseSlice)
+	if err != nil {
+		return nil, err
+	}
+	rule := &Rule{
+		ID:         ruleID,
+		Name:       spec.Name,
+		Status:     "enabled",
+		Conditions: spec.Conditions,
+		Actions:    spec.Actions,
+	}
+	return rule, nil
+}
+
+// DeleteRule deletes the rule with the given id from the bridge.
+func (b *Bridge) DeleteRule(id string) error {
+	return b.DeleteRuleContext(context.Background(), id)
+}
+
+// DeleteRuleContext is like DeleteRule, the request is bound to the given context.
+func (b *Bridge) DeleteRuleContext(ctx context.Context, id string) error {
+	_, err := b.sendAPIRequest(ctx, "DELETE", b.URL()+"/rules/"+id, nil)
+	return err
+}
@@ -0,0 +1,297 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Scene holds a stored look for a set of lights on the bridge
+type Scene struct {
+	ID          string   `json:"-"`           // id of the scene
+	Name        string   `json:"name"`        // name of the scene
+	Lights      []string `json:"lights"`      // ids of the lights in the scene
+	Owner       string   `json:"owner"`       // whitelist user that created or last modified the scene
+	Recycle     bool     `json:"recycle"`     // whether the scene can be automatically deleted by the bridge
+	Locked      bool     `json:"locked"`      // whether the scene is used by a schedule or rule and cannot be deleted
+	LastUpdated *Time    `json:"lastupdated"` // time at which the scene was last updated
+}
+
+// GetAllScenes returns all scenes stored on the bridge, sorted by their ID.
+func (b *Bridge) GetAllScenes() ([]*Scene, error) {
+	return b.GetAllScenesContext(context.Background())
+}
+
+// GetAllScenesContext is like GetAllScenes, the request is bound to the given context.
+func (b *Bridge) GetAllScenesContext(ctx context.Context) ([]*Scene, error) {
+	scenesMap := map[string]*Scene{}
+	err := b.getJSON(ctx, b.URL()+"/scenes", &scenesMap)
+	if err != nil {
+		return nil, err
+	}
+	scenes := make([]*Scene, 0, len(scenesMap))
+	for sceneID, scene := range scenesMap {
+		scene.ID = sceneID
+		scenes = append(scenes, scene)
+	}
+	sort.Slice(scenes, func(i, j int) bool {
+		return lessNumericID(scenes[i].ID, scenes[j].ID)
+	})
+	return scenes, nil
+}
+
+// CreateScene creates a new scene with the given name, capturing the current state of the given lights.
+// The returned scene has the ID that was generated by the bridge.
+// The name must have a length between 1 and 32 characters and at least one light id must be given.
+func (b *Bridge) CreateScene(name string, lightIDs []string) (*Scene, error) {
+	return b.CreateSceneContext(context.Background(), name, lightIDs)
+}
+
+// CreateSceneContext is like CreateScene, the request is bound to the given context.
+func (b *Bridge) CreateSceneContext(ctx context.Context, name string, lightIDs []string) (*Scene, error) {
+	if n := utf8.RuneCountInString(name); n < 1 || n > 32 {
+		return nil, errors.New("scene name must have a length between 1 and 32 characters")
+	}
+	if len(lightIDs) == 0 {
+		return nil, errors.New("a scene must contain at least one light")
+	}
+	requestData := map[string]interface{}{
+		"name":    name,
+		"lights":  lightIDs,
+		"recycle": false,
+	}
+	apiResponseSlice, err := b.sendAPIRequest(ctx, "POST", b.URL()+"/scenes", requestData)
+	if err != nil {
+		return nil, err
+	}
+	sceneID, err := createdID(apiResponseSlice)
+	if err != nil {
+		return nil, err
+	}
+	return &Scene{ID: sceneID, Name: name, Lights: lightIDs}, nil
+}
+
+// SceneAction holds the state of a single light in a scene created with CreateSceneV2.
+// Fields that are nil are not stored in the scene, the light keeps that part of its state when the scene is recalled.
+type SceneAction struct {
+	Light      string        // id of the light resource in the v2 api, see LightV2.ID
+	On         bool          // On/Off state of the light. On=true, Off=false
+	Brightness *float64      // brightness in percent, from 0 to 100
+	XY         *XY           // color in CIE color space, must not be combined with Mirek
+	Mirek      *uint16       // color temperature in mirek (mired), must not be combined with XY
+	Transition time.Duration // duration of the transition to the state when the scene is recalled, 0 for the default
+}
+
+// validate checks the action before it is sent to the bridge.
+func (a SceneAction) validate() error {
+	if len(a.Light) == 0 {
+		return errors.New("scene action must target a light")
+	}
+	if a.Brightness != nil && (*a.Brightness < 0 || *a.Brightness > 100) {
+		return fmt.Errorf("brightness %v for light %s out of range, must be between 0 and 100 percent", *a.Brightness, a.Light)
+	}
+	if a.XY != nil && a.Mirek != nil {
+		return fmt.Errorf("scene action for light %s must not set both a color and a color temperature", a.Light)
+	}
+	return nil
+}
+
+// v2Action returns the action in the form of the v2 api.
+func (a SceneAction) v2Action() map[string]interface{} {
+	action := map[string]interface{}{
+		"on": map[string]bool{"on": a.On},
+	}
+	if a.Brightness != nil {
+		action["dimming"] = map[string]float64{"brightness": *a.Brightness}
+	}
+	if a.XY != nil {
+		action["color"] = map[string]interface{}{"xy": map[string]float64{"x": a.XY.X(), "y": a.XY.Y()}}
+	}
+	if a.Mirek != nil {
+		action["color_temperature"] = map[string]uint16{"mirek": *a.Mirek}
+	}
+	if a.Transition > 0 {
+		action["dynamics"] = map[string]int64{"duration": a.Transition.Milliseconds()}
+	}
+	return map[string]interface{}{
+		"target": ResourceIdentifier{RID: a.Light, RType: "light"},
+		"action": action,
+	}
+}
+
+// CreateSceneV2 creates a new scene with the given name from explicit per-light states, rather than capturing
+// the current state of the lights like CreateScene does. The v2 api requires a scene to belong to a room or zone,
+// group references that resource, e.g. ResourceIdentifier{RID: "...", RType: "room"}.
+// The name must have a length between 1 and 32 characters and at least one action must be given,
+// invalid actions are rejected without contacting the bridge. The returned scene has the v1 ID of the new scene,
+// for use with RecallScene, and the v1 IDs of its lights.
+func (b *Bridge) CreateSceneV2(name string, group ResourceIdentifier, actions []SceneAction) (*Scene, error) {
+	return b.CreateSceneV2Context(context.Background(), name, group, actions)
+}
+
+// CreateSceneV2Context is like CreateSceneV2, the requests are bound to the given context.
+func (b *Bridge) CreateSceneV2Context(ctx context.Context, name string, group ResourceIdentifier, actions []SceneAction) (*Scene, error) {
+	if n := utf8.RuneCountInString(name); n < 1 || n > 32 {
+		return nil, errors.New("scene name must have a length between 1 and 32 characters")
+	}
+	if group.RType != "room" && group.RType != "zone" {
+		return nil, fmt.Errorf("invalid scene group type %q, must be %q or %q", group.RType, "room", "zone")
+	}
+	if len(actions) == 0 {
+		return nil, errors.New("a scene must contain at least one light")
+	}
+	v2Actions := make([]map[string]interface{}, 0, len(actions))
+	for _, action := range actions {
+		err := action.validate()
+		if err != nil {
+			return nil, err
+		}
+		v2Actions = append(v2Actions, action.v2Action())
+	}
+
+	requestData := map[string]interface{}{
+		"type":     "scene",
+		"metadata": map[string]string{"name": name},
+		"group":    group,
+		"actions":  v2Actions,
+	}
+	created := make([]ResourceIdentifier, 0, 1)
+	err := b.v2Request(ctx, "POST", "/resource/scene", requestData, &created)
+	if err != nil {
+		return nil, err
+	}
+	if len(created) == 0 {
+		return nil, errors.New("bridge did not return the id of the created scene")
+	}
+
+	// the v1 ids of the scene and its lights are looked up, so the scene can be used with the v1 api
+	scenes := make([]struct {
+		IDV1 string `json:"id_v1"`
+	}, 0, 1)
+	err = b.v2Request(ctx, "GET", "/resource/scene/"+created[0].RID, nil, &scenes)
+	if err != nil {
+		return nil, err
+	}
+	scene := &Scene{Name: name, Lights: make([]string, 0, len(actions))}
+	if len(scenes) > 0 {
+		scene.ID = strings.TrimPrefix(scenes[0].IDV1, "/scenes/")
+	}
+	lights, err := b.GetLightsV2Context(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range actions {
+		for _, light := range lights {
+			if light.ID == action.Light {
+				scene.Lights = append(scene.Lights, strings.TrimPrefix(light.IDV1, "/lights/"))
+				break
+			}
+		}
+	}
+	return scene, nil
+}
+
+// DeleteScene deletes the scene with the given id from the bridge.
+// An *APIError is returned when the scene does not exist.
+func (b *Bridge) DeleteScene(id string) error {
+	return b.DeleteSceneContext(context.Background(), id)
+}
+
+// DeleteSceneContext is like DeleteScene, the request is bound to the given context.
+func (b *Bridge) DeleteSceneContext(ctx context.Context, id string) error {
+	_, err := b.sendAPIRequest(ctx, "DELETE", b.URL()+"/scenes/"+id, nil)
+	return err
+}
+
+// RecallScene applies the scene with sceneID to the lights of the scene that are in the group with groupID.
+// Use AllLightsGroupID to apply the scene to all of its lights.
+// Like a state change of a group, the recall is sent through the CommandQueue of the bridge when it has one.
+// An *APIError is returned when the scene does not exist.
+func (b *Bridge) RecallScene(sceneID string, groupID string) error {
+	return b.RecallSceneContext(context.Background(), sceneID, groupID)
+}
+
+// RecallSceneContext is like RecallScene, the request is bound to the given context.
+func (b *Bridge) RecallSceneContext(ctx context.Context, sceneID string, groupID string) error {
+	return b.sendStateChange(ctx, b.URL()+"/groups/"+groupID+"/action", map[string]string{"scene": sceneID})
+}
+
+// RecallSceneWithTransition applies the scene like RecallScene, with a transition of the given duration.
+// Unlike a plain recall, which lets the lights snap to the scene, this also works smoothly with the v1 api.
+func (b *Bridge) RecallSceneWithTransition(sceneID string, groupID string, transition time.Duration) error {
+	return b.RecallSceneWithTransitionContext(context.Background(), sceneID, groupID, transition)
+}
+
+// RecallSceneWithTransitionContext is like RecallSceneWithTransition, the request is bound to the given context.
+// The scene is recalled with the v1 api, sending the transition time in the same request.
+func (b *Bridge) RecallSceneWithTransitionContext(ctx context.Context, sceneID string, groupID string, transition time.Duration) error {
+	recall := struct {
+		Scene          string  `json:"scene"`
+		TransitionTime *uint16 `json:"transitiontime"`
+	}{
+		Scene:          sceneID,
+		TransitionTime: TransitionTime(transition),
+	}
+	return b.sendStateChange(ctx, b.URL()+"/groups/"+groupID+"/action", recall)
+}
+
+// RecallOptions holds overrides for recalling a scene with RecallSceneWithOptions.
+type RecallOptions struct {
+	Brightness *float64      // brightness in percent (0..100) for all lights of the scene, nil to use the scene's brightness
+	Transition time.Duration // duration of the transition to the scene, 0 for the default transition
+}
+
+// RecallSceneWithOptions applies the scene like RecallScene, overriding the brightness and transition of the scene.
+// The brightness override requires the v2 api, see UseV2. With the v1 api an error is returned when it is set.
+// The group is only used with the v1 api, the v2 api applies the scene to the group it belongs to.
+func (b *Bridge) RecallSceneWithOptions(sceneID string, groupID string, options RecallOptions) error {
+	return b.RecallSceneWithOptionsContext(context.Background(), sceneID, groupID, options)
+}
+
+// RecallSceneWithOptionsContext is like RecallSceneWithOptions, the requests are bound to the given context.
+func (b *Bridge) RecallSceneWithOptionsContext(ctx context.Context, sceneID string, groupID string, options RecallOptions) error {
+	if options.Brightness != nil && (*options.Brightness < 0 || *options.Brightness > 100) {
+		return fmt.Errorf("brightness %v out of range, must be between 0 and 100 percent", *options.Brightness)
+	}
+	if !b.usesV2() {
+		if options.Brightness != nil {
+			return errors.New("recalling a scene with a brightness override requires the v2 api, see UseV2")
+		}
+		if options.Transition > 0 {
+			return b.RecallSceneWithTransitionContext(ctx, sceneID, groupID, options.Transition)
+		}
+		return b.RecallSceneContext(ctx, sceneID, groupID)
+	}
+
+	scenes := make([]struct {
+		ID   string `json:"id"`
+		IDV1 string `json:"id_v1"`
+	}, 0)
+	err := b.v2Request(ctx, "GET", "/resource/scene", nil, &scenes)
+	if err != nil {
+		return err
+	}
+	rid := ""
+	for _, scene := range scenes {
+		if scene.IDV1 == "/scenes/"+sceneID {
+			rid = scene.ID
+			break
+		}
+	}
+	if len(rid) == 0 {
+		return fmt.Errorf("scene %s not found in the v2 api", sceneID)
+	}
+
+	recall := map[string]interface{}{"action": "active"}
+	if options.Brightness != nil {
+		recall["dimming"] = map[string]float64{"brightness": *options.Brightness}
+	}
+	if options.Transition > 0 {
+		recall["duration"] = options.Transition.Milliseconds()
+	}
+	return b.v2Request(ctx, "PUT", "/resource/scene/"+rid, map[string]interface{}{"recall": recall}, nil)
+}
@@ -0,0 +1,88 @@
+package hue_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GeertJohan/go.hue"
+	"github.com/GeertJohan/go.hue/huetest"
+)
+
+func TestRecallSceneWithTransition(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	b := srv.Bridge()
+
+	err := b.RecallSceneWithTransition("abc123", "2", 1500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("RecallSceneWithTransition: %v", err)
+	}
+	commands := srv.CommandsTo("/groups/2/action")
+	if len(commands) != 1 {
+		t.Fatalf("RecallSceneWithTransition sent %d commands to /groups/2/action, want 1", len(commands))
+	}
+	if commands[0].Method != "PUT" {
+		t.Errorf("RecallSceneWithTransition used method %s, want PUT", commands[0].Method)
+	}
+	body := struct {
+		Scene          *string `json:"scene"`
+		TransitionTime *uint16 `json:"transitiontime"`
+	}{}
+	err = json.Unmarshal(commands[0].Body, &body)
+	if err != nil {
+		t.Fatalf("decoding body %s: %v", commands[0].Body, err)
+	}
+	if body.Scene == nil || *body.Scene != "abc123" {
+		t.Errorf("body %s has no scene abc123", commands[0].Body)
+	}
+	if body.TransitionTime == nil || *body.TransitionTime != 15 {
+		t.Errorf("body %s has no transitiontime 15", commands[0].Body)
+	}
+}
+
+func TestRecallSceneWithOptionsV1(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	b := srv.Bridge()
+
+	for _, brightness := range []float64{50, 150} {
+		err := b.RecallSceneWithOptions("abc123", "2", hue.RecallOptions{Brightness: &brightness})
+		if err == nil {
+			t.Errorf("RecallSceneWithOptions with brightness %v and the v1 api returned no error", brightness)
+		}
+	}
+	if commands := srv.Commands(); len(commands) != 0 {
+		t.Errorf("RecallSceneWithOptions with a brightness and the v1 api sent %d commands, want none", len(commands))
+	}
+
+	err := b.RecallSceneWithOptions("abc123", "2", hue.RecallOptions{Transition: time.Second})
+	if err != nil {
+		t.Fatalf("RecallSceneWithOptions with a transition: %v", err)
+	}
+	commands := srv.CommandsTo("/groups/2/action")
+	if len(commands) != 1 || string(commands[0].Body) != `{"scene":"abc123","transitiontime":10}` {
+		t.Errorf("RecallSceneWithOptions with a transition sent %+v, want the scene with transitiontime 10", commands)
+	}
+}
+
+func TestCreateSceneCountsCharacters(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	b := srv.Bridge()
+
+	// 32 characters, but more than 32 bytes
+	name := strings.Repeat("ä", 32)
+	_, err := b.CreateScene(name, []string{"1"})
+	if err != nil {
+		t.Errorf("CreateScene with a name of 32 characters: %v", err)
+	}
+	_, err = b.CreateScene(name+"ä", []string{"1"})
+	if err == nil {
+		t.Error("CreateScene with a name of 33 characters returned no error")
+	}
+	if commands := srv.CommandsTo("/scenes"); len(commands) != 1 {
+		t.Errorf("CreateScene sent %d commands to /scenes, want 1", len(commands))
+	}
+}
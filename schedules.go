@@ -0,0 +1,118 @@
+package hue
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Command is an api call that is executed by the bridge, e.g. as part of a schedule.
+type Command struct {
+	Address string      `json:"address"` // path of the resource, including /api/<username>, e.g. “/api/<username>/lights/1/state”
+	Method  string      `json:"method"`  // http method of the call: “POST”, “PUT” or “DELETE”
+	Body    interface{} `json:"body"`    // body of the call, e.g. a LightStateChange
+}
+
+// Schedule holds a command that is executed by the bridge at a given time.
+type Schedule struct {
+	ID          string  `json:"-"`           // id of the schedule
+	Name        string  `json:"name"`        // name of the schedule
+	Description string  `json:"description"` // description of the schedule
+	Command     Command `json:"command"`     // command that is executed when the schedule expires
+	LocalTime   string  `json:"localtime"`   // local time at which the schedule expires
+	Status      string  `json:"status"`      // “enabled” or “disabled”
+	Created     *Time   `json:"created"`     // time at which the schedule was created
+	AutoDelete  bool    `json:"autodelete"`  // whether the schedule is removed after it expires
+}
+
+// ScheduleSpec holds the values for a schedule that is created with CreateSchedule.
+type ScheduleSpec struct {
+	Name        string  `json:"name,omitempty"`        // length 0..32. Name of the schedule.
+	Description string  `json:"description,omitempty"` // length 0..64. Description of the schedule.
+	Command     Command `json:"command"`               // command that is executed when the schedule expires
+	LocalTime   string  `json:"localtime"`             // local time at which the schedule expires, see AbsoluteLocalTime
+
+	When *LocalTime `json:"-"` // structured local time at which the schedule expires, overrides LocalTime when set
+}
+
+// AbsoluteLocalTime formats t as absolute local time for use in ScheduleSpec.LocalTime.
+// The wall clock of t is used, it is interpreted by the bridge in its own timezone.
+// Use ScheduleSpec.When for recurring schedules and timers.
+func AbsoluteLocalTime(t time.Time) string {
+	return t.Format(timeLayout)
+}
+
+// When parses the LocalTime of the schedule into its structured form.
+func (s *Schedule) When() (LocalTime, error) {
+	return ParseLocalTime(s.LocalTime)
+}
+
+// GetAllSchedules returns all schedules stored on the bridge, sorted by their numeric ID.
+func (b *Bridge) GetAllSchedules() ([]*Schedule, error) {
+	return b.GetAllSchedulesContext(context.Background())
+}
+
+// GetAllSchedulesContext is like GetAllSchedules, the request is bound to the given context.
+func (b *Bridge) GetAllSchedulesContext(ctx context.Context) ([]*Schedule, error) {
+	schedulesMap := map[string]*Schedule{}
+	err := b.getJSON(ctx, b.URL()+"/schedules", &schedulesMap)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]*Schedule, 0, len(schedulesMap))
+	for scheduleID, schedule := range schedulesMap {
+		schedule.ID = scheduleID
+		schedules = append(schedules, schedule)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return lessNumericID(schedules[i].ID, schedules[j].ID)
+	})
+	return schedules, nil
+}
+
+// CreateSchedule creates a new schedule on the bridge.
+// When the spec has When set, it must be representable by the bridge, otherwise an error is returned without contacting it.
+// The returned schedule has the ID that was assigned by the bridge.
+func (b *Bridge) CreateSchedule(s ScheduleSpec) (*Schedule, error) {
+	return b.CreateScheduleContext(context.Background(), s)
+}
+
+// CreateScheduleContext is like CreateSchedule, the request is bound to the given context.
+func (b *Bridge) CreateScheduleContext(ctx context.Context, s ScheduleSpec) (*Schedule, error) {
+	if s.When != nil {
+		err := s.When.validate()
+		if err != nil {
+			return nil, err
+		}
+		s.LocalTime = s.When.String()
+	}
+	apiResponseSlice, err := b.sendAPIRequest(ctx, "POST", b.URL()+"/schedules", s)
+	if err != nil {
+		return nil, err
+	}
+	scheduleID, err := createdID(apiResponseSlice)
+	if err != nil {
+		return nil, err
+	}
+	schedule := &Schedule{
+		ID:          scheduleID,
+		Name:        s.Name,
+		Description: s.Description,
+		Command:     s.Command,
+		LocalTime:   s.LocalTime,
+		Status:      "enabled",
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule deletes the schedule with the given id from the bridge.
+// An *APIError is returned when the schedule does not exist.
+func (b *Bridge) DeleteSchedule(id string) error {
+	return b.DeleteScheduleContext(context.Background(), id)
+}
+
+// DeleteScheduleContext is like DeleteSchedule, the request is bound to the given context.
+func (b *Bridge) DeleteScheduleContext(ctx context.Context, id string) error {
+	_, err := b.sendAPIRequest(ctx, "DELETE", b.URL()+"/schedules/"+id, nil)
+	return err
+}
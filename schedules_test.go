@@ -0,0 +1,35 @@
+package hue_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GeertJohan/go.hue"
+	"github.com/GeertJohan/go.hue/huetest"
+)
+
+func TestCreateScheduleInvalidWhen(t *testing.T) {
+	srv := huetest.NewServer()
+	defer srv.Close()
+	b := srv.Bridge()
+
+	for _, when := range []hue.LocalTime{{}, {Random: time.Minute}, hue.TimerTime(25 * time.Hour)} {
+		_, err := b.CreateSchedule(hue.ScheduleSpec{Name: "Wake up", When: &when})
+		if err == nil {
+			t.Errorf("CreateSchedule with When %+v returned no error", when)
+		}
+	}
+	if commands := srv.Commands(); len(commands) != 0 {
+		t.Errorf("CreateSchedule with an invalid When sent %d commands, want none", len(commands))
+	}
+
+	when := hue.RecurringTime(7*time.Hour, time.Monday, time.Friday)
+	_, err := b.CreateSchedule(hue.ScheduleSpec{Name: "Wake up", When: &when})
+	if err != nil {
+		t.Fatalf("CreateSchedule with a recurring When: %v", err)
+	}
+	commands := srv.CommandsTo("/schedules")
+	if len(commands) != 1 {
+		t.Fatalf("CreateSchedule sent %d commands to /schedules, want 1", len(commands))
+	}
+}
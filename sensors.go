@@ -0,0 +1,279 @@
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Sensor types with a typed state accessor.
+const (
+	SensorTypeZLLPresence    = "ZLLPresence"    // motion sensor
+	SensorTypeZLLTemperature = "ZLLTemperature" // temperature sensor
+	SensorTypeZLLLightLevel  = "ZLLLightLevel"  // light level sensor
+	SensorTypeZLLSwitch      = "ZLLSwitch"      // dimmer switch
+	SensorTypeZGPSwitch      = "ZGPSwitch"      // tap switch
+)
+
+// Virtual sensor types that can be created with CreateCLIPSensor.
+const (
+	SensorTypeCLIPGenericStatus = "CLIPGenericStatus" // holds an integer status
+	SensorTypeCLIPGenericFlag   = "CLIPGenericFlag"   // holds a boolean flag
+)
+
+// Buttons of a dimmer switch, as decoded by DecodeButtonEvent.
+const (
+	DimmerButtonOn      = 1 // the on button
+	DimmerButtonDimUp   = 2 // the brighter button
+	DimmerButtonDimDown = 3 // the darker button
+	DimmerButtonOff     = 4 // the off button
+)
+
+// Button event codes of a tap switch, for its buttons 1 to 4.
+const (
+	TapButtonEvent1 = 34
+	TapButtonEvent2 = 16
+	TapButtonEvent3 = 17
+	TapButtonEvent4 = 18
+)
+
+// Button actions, as decoded by DecodeButtonEvent.
+const (
+	ButtonActionInitialPress = "initial_press" // the button was pressed
+	ButtonActionHold         = "hold"          // the button is held down, repeated while held
+	ButtonActionShortRelease = "short_release" // the button was released after a short press
+	ButtonActionLongRelease  = "long_release"  // the button was released after being held
+	ButtonActionPress        = "press"         // the button was pressed, tap switches report no other actions
+)
+
+// Sensor holds a sensor known by the bridge.
+// The state and config of the sensor depend on its type, typed accessors are available for common sensor types.
+type Sensor struct {
+	ID               string                 `json:"-"`                // id of the sensor
+	Type             string                 `json:"type"`             // type of the sensor, e.g. “ZLLPresence”
+	Name             string                 `json:"name"`             // name of the sensor
+	ModelID          string                 `json:"modelid"`          // hardware model of the sensor
+	ManufacturerName string                 `json:"manufacturername"` // manufacturer of the sensor
+	SwVersion        string                 `json:"swversion"`        // software version of the sensor
+	UniqueID         string                 `json:"uniqueid"`         // unique id of the sensor, e.g. its MAC address
+	State            map[string]interface{} `json:"state"`            // state of the sensor, depending on its type
+	Config           map[string]interface{} `json:"config"`           // config of the sensor, depending on its type
+}
+
+// CLIPSensorSpec holds the values for a virtual sensor that is created with CreateCLIPSensor.
+type CLIPSensorSpec struct {
+	Name             string `json:"name"`             // length 0..32. Name of the sensor.
+	Type             string `json:"type"`             // type of the sensor, e.g. SensorTypeCLIPGenericStatus
+	ModelID          string `json:"modelid"`          // length 6..32. Model of the sensor, chosen by the application.
+	ManufacturerName string `json:"manufacturername"` // length 6..32. Manufacturer of the sensor, chosen by the application.
+	SwVersion        string `json:"swversion"`        // length 1..16. Software version of the sensor, chosen by the application.
+	UniqueID         string `json:"uniqueid"`         // length 0..32. Unique id of the sensor, chosen by the application.
+}
+
+// PresenceState holds the state of a ZLLPresence sensor.
+type PresenceState struct {
+	Presence    bool  `json:"presence"`    // whether motion is detected
+	LastUpdated *Time `json:"lastupdated"` // time at which the state last changed
+}
+
+// TemperatureState holds the state of a ZLLTemperature sensor.
+type TemperatureState struct {
+	Temperature int   `json:"temperature"` // temperature in 0.01 degrees Celsius
+	LastUpdated *Time `json:"lastupdated"` // time at which the state last changed
+}
+
+// LightLevelState holds the state of a ZLLLightLevel sensor.
+type LightLevelState struct {
+	LightLevel  uint16 `json:"lightlevel"`  // light level as 10000*log10(lux)+1
+	Dark        bool   `json:"dark"`        // whether the light level is below the dark threshold
+	Daylight    bool   `json:"daylight"`    // whether the light level is above the daylight threshold
+	LastUpdated *Time  `json:"lastupdated"` // time at which the state last changed
+}
+
+// ButtonState holds the state of a ZLLSwitch or ZGPSwitch sensor.
+type ButtonState struct {
+	ButtonEvent int   `json:"buttonevent"` // code of the last button event, see DecodeButtonEvent
+	LastUpdated *Time `json:"lastupdated"` // time at which the last button event occurred
+}
+
+// GetAllSensors returns all sensors known by the bridge, sorted by their numeric ID.
+// Sensors of any type are returned, their state and config are available as generic maps.
+func (b *Bridge) GetAllSensors() ([]*Sensor, error) {
+	return b.GetAllSensorsContext(context.Background())
+}
+
+// GetAllSensorsContext is like GetAllSensors, the request is bound to the given context.
+func (b *Bridge) GetAllSensorsContext(ctx context.Context) ([]*Sensor, error) {
+	sensorsMap := map[string]*Sensor{}
+	err := b.getJSON(ctx, b.URL()+"/sensors", &sensorsMap)
+	if err != nil {
+		return nil, err
+	}
+	sensors := make([]*Sensor, 0, len(sensorsMap))
+	for sensorID, sensor := range sensorsMap {
+		sensor.ID = sensorID
+		sensors = append(sensors, sensor)
+	}
+	sort.Slice(sensors, func(i, j int) bool {
+		return lessNumericID(sensors[i].ID, sensors[j].ID)
+	})
+	return sensors, nil
+}
+
+// CreateCLIPSensor creates a new virtual sensor on the bridge, which applications and rules can use as shared state.
+// The returned sensor has the ID that was assigned by the bridge.
+func (b *Bridge) CreateCLIPSensor(spec CLIPSensorSpec) (*Sensor, error) {
+	return b.CreateCLIPSensorContext(context.Background(), spec)
+}
+
+// CreateCLIPSensorContext is like CreateCLIPSensor, the request is bound to the given context.
+func (b *Bridge) CreateCLIPSensorContext(ctx context.Context, spec CLIPSensorSpec) (*Sensor, error) {
+	apiResponseSlice, err := b.sendAPIRequest(ctx, "POST", b.URL()+"/sensors", spec)
+	if err != nil {
+		return nil, err
+	}
+	sensorID, err := createdID(apiResponseSlice)
+	if err != nil {
+		return nil, err
+	}
+	sensor := &Sensor{
+		ID:               sensorID,
+		Type:             spec.Type,
+		Name:             spec.Name,
+		ModelID:          spec.ModelID,
+		ManufacturerName: spec.ManufacturerName,
+		SwVersion:        spec.SwVersion,
+		UniqueID:         spec.UniqueID,
+		State:            map[string]interface{}{},
+		Config:           map[string]interface{}{},
+	}
+	return sensor, nil
+}
+
+// SetSensorStatus sets the status of the CLIPGenericStatus sensor with the given id.
+// Rules can use the status in their conditions, with the address “/sensors/<id>/state/status”.
+func (b *Bridge) SetSensorStatus(id string, status int) error {
+	return b.SetSensorStatusContext(context.Background(), id, status)
+}
+
+// SetSensorStatusContext is like SetSensorStatus, the request is bound to the given context.
+func (b *Bridge) SetSensorStatusContext(ctx context.Context, id string, status int) error {
+	_, err := b.sendAPIRequest(ctx, "PUT", b.URL()+"/sensors/"+id+"/state", map[string]int{"status": status})
+	return err
+}
+
+// SetSensorFlag sets the flag of the CLIPGenericFlag sensor with the given id.
+// Rules can use the flag in their conditions, with the address “/sensors/<id>/state/flag”.
+func (b *Bridge) SetSensorFlag(id string, flag bool) error {
+	return b.SetSensorFlagContext(context.Background(), id, flag)
+}
+
+// SetSensorFlagContext is like SetSensorFlag, the request is bound to the given context.
+func (b *Bridge) SetSensorFlagContext(ctx context.Context, id string, flag bool) error {
+	_, err := b.sendAPIRequest(ctx, "PUT", b.URL()+"/sensors/"+id+"/state", map[string]bool{"flag": flag})
+	return err
+}
+
+// PresenceState returns the state of a ZLLPresence sensor.
+func (s *Sensor) PresenceState() (*PresenceState, error) {
+	state := &PresenceState{}
+	err := s.decodeState(SensorTypeZLLPresence, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// TemperatureState returns the state of a ZLLTemperature sensor.
+func (s *Sensor) TemperatureState() (*TemperatureState, error) {
+	state := &TemperatureState{}
+	err := s.decodeState(SensorTypeZLLTemperature, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// LightLevelState returns the state of a ZLLLightLevel sensor.
+func (s *Sensor) LightLevelState() (*LightLevelState, error) {
+	state := &LightLevelState{}
+	err := s.decodeState(SensorTypeZLLLightLevel, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ButtonState returns the state of a ZLLSwitch or ZGPSwitch sensor.
+func (s *Sensor) ButtonState() (*ButtonState, error) {
+	sensorType := SensorTypeZLLSwitch
+	if s.Type == SensorTypeZGPSwitch {
+		sensorType = SensorTypeZGPSwitch
+	}
+	state := &ButtonState{}
+	err := s.decodeState(sensorType, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ButtonEvent returns the button and action of the last button event of a ZLLSwitch or ZGPSwitch sensor.
+func (s *Sensor) ButtonEvent() (button int, action string, err error) {
+	state, err := s.ButtonState()
+	if err != nil {
+		return 0, "", err
+	}
+	return DecodeButtonEvent(s.Type, state.ButtonEvent)
+}
+
+// DecodeButtonEvent decodes the buttonevent code reported by a sensor of the given type into a button and action.
+// For a ZLLSwitch (dimmer switch) the button is one of the DimmerButton constants and the action any of the
+// ButtonAction constants except ButtonActionPress. For a ZGPSwitch (tap switch) the button is 1 to 4 and the
+// action is always ButtonActionPress. An error is returned for other sensor types and unknown codes.
+func DecodeButtonEvent(sensorType string, buttonEvent int) (button int, action string, err error) {
+	switch sensorType {
+	case SensorTypeZLLSwitch:
+		button = buttonEvent / 1000
+		if button < DimmerButtonOn || button > DimmerButtonOff {
+			break
+		}
+		switch buttonEvent % 1000 {
+		case 0:
+			return button, ButtonActionInitialPress, nil
+		case 1:
+			return button, ButtonActionHold, nil
+		case 2:
+			return button, ButtonActionShortRelease, nil
+		case 3:
+			return button, ButtonActionLongRelease, nil
+		}
+	case SensorTypeZGPSwitch:
+		switch buttonEvent {
+		case TapButtonEvent1:
+			return 1, ButtonActionPress, nil
+		case TapButtonEvent2:
+			return 2, ButtonActionPress, nil
+		case TapButtonEvent3:
+			return 3, ButtonActionPress, nil
+		case TapButtonEvent4:
+			return 4, ButtonActionPress, nil
+		}
+	default:
+		return 0, "", fmt.Errorf("sensor type %q has no button events", sensorType)
+	}
+	return 0, "", fmt.Errorf("unknown button event %d for sensor type %q", buttonEvent, sensorType)
+}
+
+// decodeState decodes the generic state of the sensor into v, after checking the sensor has the expected type.
+func (s *Sensor) decodeState(sensorType string, v interface{}) error {
+	if s.Type != sensorType {
+		return fmt.Errorf("sensor %s has type %q, not %q", s.ID, s.Type, sensorType)
+	}
+	data, err := json.Marshal(s.State)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
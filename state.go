@@ -0,0 +1,69 @@
+package hue
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LightStateChange holds changes to the state of a light or group.
+// Fields that are nil are omitted from the change and are left unchanged on the light.
+type LightStateChange struct {
+	On             *bool       `json:"on,omitempty"`             // On/Off state of the light. On=true, Off=false
+	Bri            *uint8      `json:"bri,omitempty"`            // Brightness of the light, from 1 to 254.
+	Hue            *uint16     `json:"hue,omitempty"`            // Hue of the light. This is a wrapping value between 0 and 65535.
+	Sat            *uint8      `json:"sat,omitempty"`            // Saturation of the light, from 0 (white) to 254 (most saturated).
+	CT             *uint16     `json:"ct,omitempty"`             // The Mired Color temperature of the light.
+	XY             *XY         `json:"xy,omitempty"`             // The x and y coordinates of a color in CIE color space.
+	Effect         *string     `json:"effect,omitempty"`         // The dynamic effect of the light, EffectNone or EffectColorLoop.
+	Alert          *string     `json:"alert,omitempty"`          // The alert effect of the light, AlertNone, AlertSelect or AlertLSelect.
+	TransitionTime *uint16     `json:"transitiontime,omitempty"` // Duration of the transition to the new state, in multiples of 100ms.
+	BriInc         *int        `json:"bri_inc,omitempty"`        // Relative change of the brightness, from -254 to 254.
+	HueInc         *int        `json:"hue_inc,omitempty"`        // Relative change of the hue, from -65534 to 65534.
+	SatInc         *int        `json:"sat_inc,omitempty"`        // Relative change of the saturation, from -254 to 254.
+	CTInc          *int        `json:"ct_inc,omitempty"`         // Relative change of the color temperature, from -65534 to 65534.
+	XYInc          *[2]float64 `json:"xy_inc,omitempty"`         // Relative change of the x and y coordinates, each from -0.5 to 0.5.
+}
+
+// Validate checks that the relative changes in c are within their documented ranges.
+// SetState validates the change before it is sent.
+func (c LightStateChange) Validate() error {
+	if err := validateIncrement("bri_inc", c.BriInc, 254); err != nil {
+		return err
+	}
+	if err := validateIncrement("hue_inc", c.HueInc, 65534); err != nil {
+		return err
+	}
+	if err := validateIncrement("sat_inc", c.SatInc, 254); err != nil {
+		return err
+	}
+	if err := validateIncrement("ct_inc", c.CTInc, 65534); err != nil {
+		return err
+	}
+	if c.XYInc != nil {
+		for _, inc := range c.XYInc {
+			if math.Abs(inc) > 0.5 {
+				return fmt.Errorf("xy_inc %v out of range, each coordinate must be between -0.5 and 0.5", *c.XYInc)
+			}
+		}
+	}
+	return nil
+}
+
+// validateIncrement returns an error when the increment is set and outside -limit..limit.
+func validateIncrement(name string, inc *int, limit int) error {
+	if inc != nil && (*inc < -limit || *inc > limit) {
+		return fmt.Errorf("%s %d out of range, must be between %d and %d", name, *inc, -limit, limit)
+	}
+	return nil
+}
+
+// TransitionTime converts a duration to a transition time for use in LightStateChange.TransitionTime.
+func TransitionTime(d time.Duration) *uint16 {
+	deciseconds := d / (100 * time.Millisecond)
+	if deciseconds > math.MaxUint16 {
+		deciseconds = math.MaxUint16
+	}
+	transitionTime := uint16(deciseconds)
+	return &transitionTime
+}
@@ -0,0 +1,62 @@
+package hue
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// storedBridge is the on-disk representation of a Bridge used by SaveBridge and LoadBridge
+type storedBridge struct {
+	IP        string `json:"ip"`
+	Username  string `json:"username"`
+	BridgeID  string `json:"bridgeid,omitempty"`
+	ClientKey string `json:"clientkey,omitempty"`
+}
+
+// SaveBridge stores the IP, Username, BridgeID and ClientKey of the bridge as json in the file at path.
+// The username and client key are secrets, so the file is created with permissions 0600.
+// An existing file is overwritten and its permissions are set to 0600.
+func SaveBridge(b *Bridge, path string) error {
+	ip, username := b.credentials()
+	data, err := json.MarshalIndent(storedBridge{
+		IP:        ip,
+		Username:  username,
+		BridgeID:  b.bridgeID(),
+		ClientKey: b.clientKey(),
+	}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	err = file.Chmod(0600)
+	if err == nil {
+		_, err = file.Write(data)
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// LoadBridge creates a Bridge from a file that was written with SaveBridge.
+func LoadBridge(path string) (*Bridge, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stored := storedBridge{}
+	err = json.Unmarshal(data, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	b := NewBridge(stored.IP)
+	b.Username = stored.Username
+	b.BridgeID = stored.BridgeID
+	b.ClientKey = stored.ClientKey
+	return b, nil
+}
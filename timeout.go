@@ -0,0 +1,35 @@
+package hue
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// requestTimeoutKey is the context key under which WithRequestTimeout marks a context
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout returns a copy of ctx that bounds the requests of a single call to the given timeout.
+// The timeout replaces the timeout of the http client of the bridge for requests bound to the returned context,
+// so it can be both shorter (e.g. a quick toggle) and longer (e.g. a slow bridge update) than the usual timeout.
+// Canceling the returned context releases its resources, so cancel should be called once the call is done:
+//
+//	ctx, cancel := hue.WithRequestTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	err := light.SetStateContext(ctx, change)
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx = context.WithValue(ctx, requestTimeoutKey{}, timeout)
+	return context.WithTimeout(ctx, timeout)
+}
+
+// clientForContext returns the client to use for a request bound to ctx.
+// When the context was created with WithRequestTimeout, a copy of client without its own timeout is returned,
+// the deadline of the context applies instead.
+func clientForContext(ctx context.Context, client *http.Client) *http.Client {
+	if _, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); !ok || client.Timeout == 0 {
+		return client
+	}
+	contextClient := *client
+	contextClient.Timeout = 0
+	return &contextClient
+}
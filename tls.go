@@ -0,0 +1,110 @@
+package hue
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// signifyRootCA is the root certificate of Signify (Philips Hue), which signs the certificates of all bridges
+const signifyRootCA = `-----BEGIN CERTIFICATE-----
+MIICMjCCAdigAwIBAgIUO7FSLbaxikuXAljzVaurLXWmFw4wCgYIKoZIzj0EAwIw
+OTELMAkGA1UEBhMCTkwxFDASBgNVBAoMC1BoaWxpcHMgSHVlMRQwEgYDVQQDDAty
+b290LWJyaWRnZTAiGA8yMDE3MDEwMTAwMDAwMFoYDzIwMzgwMTE5MDMxNDA3WjA5
+MQswCQYDVQQGEwJOTDEUMBIGA1UECgwLUGhpbGlwcyBIdWUxFDASBgNVBAMMC3Jv
+b3QtYnJpZGdlMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEjNw2tx2AplOf9x86
+aTdvEcL1FU65QDxziKvBpW9XXSIcibAeQiKxegpq8Exbr9v6LBnYbna2VcaK0G22
+jOKkTqOBuTCBtjAPBgNVHRMBAf8EBTADAQH/MA4GA1UdDwEB/wQEAwIBhjAdBgNV
+HQ4EFgQUZ2ONTFrDT6o8ItRnKfqWKnHFGmQwdAYDVR0jBG0wa4AUZ2ONTFrDT6o8
+ItRnKfqWKnHFGmShPaQ7MDkxCzAJBgNVBAYTAk5MMRQwEgYDVQQKDAtQaGlsaXBz
+IEh1ZTEUMBIGA1UEAwwLcm9vdC1icmlkZ2WCFDuxUi22sYpLlwJY81Wrqy11phcO
+MAoGCCqGSM49BAMCA0gAMEUCIEBYYEOsa07TH7E5MJnGw557lVkORgit2Rm1h3B2
+sFgDAiEA1Fj/C3AN5psFMjo0//mrQebo0eKd3aWRx+pQY08mk48=
+-----END CERTIFICATE-----
+`
+
+// bridgeRootCAs holds the root certificates the bridge certificates are verified against,
+// the Signify root CA unless it was replaced with SetBridgeRootCA
+var (
+	bridgeRootCAsLock sync.RWMutex
+	bridgeRootCAs     = mustCertPool(signifyRootCA)
+)
+
+// mustCertPool returns a pool with the certificates in PEM format, it panics when there are none.
+func mustCertPool(pemCerts string) *x509.CertPool {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(pemCerts)) {
+		panic("hue: no valid PEM certificate found")
+	}
+	return pool
+}
+
+// SetBridgeRootCA replaces the root certificates in PEM format against which the certificates presented by bridges
+// are verified by BridgeTLSConfig. By default the Signify root CA is used, so this is only needed when
+// bridges are signed by a different root, e.g. in tests. Include the Signify root CA in pemCerts to keep trusting it.
+func SetBridgeRootCA(pemCerts []byte) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemCerts) {
+		return errors.New("no valid PEM certificate found")
+	}
+	bridgeRootCAsLock.Lock()
+	defer bridgeRootCAsLock.Unlock()
+	bridgeRootCAs = pool
+	return nil
+}
+
+// BridgeTLSConfig returns a tls.Config for https connections to the bridge with the given id (serial).
+//
+// Bridges present a certificate that is not signed by a public CA, and that has the bridge id as common name
+// instead of a hostname, so the default verification always fails. The returned config verifies the certificate
+// chain against the Signify root CA (or the roots set with SetBridgeRootCA), and checks the common name equals
+// the bridge id. The chain proves the certificate belongs to a genuine bridge, the common name that it is the
+// expected one. Bridge.InsecureSkipVerify disables both checks, which makes the connection vulnerable to
+// man-in-the-middle attacks by anyone on the network.
+func BridgeTLSConfig(bridgeID string) *tls.Config {
+	return &tls.Config{
+		// the default verification is replaced by verifyBridgeCertificate
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: verifyBridgeCertificate(bridgeID),
+	}
+}
+
+// verifyBridgeCertificate returns a function that verifies the certificate chain presented by the bridge.
+func verifyBridgeCertificate(bridgeID string) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("bridge presented no certificate")
+		}
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, rawCert := range rawCerts {
+			cert, err := x509.ParseCertificate(rawCert)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, cert)
+		}
+		leaf := certs[0]
+		if !strings.EqualFold(leaf.Subject.CommonName, bridgeID) {
+			return fmt.Errorf("bridge certificate is for %q, expected bridge id %q", leaf.Subject.CommonName, bridgeID)
+		}
+
+		bridgeRootCAsLock.RLock()
+		roots := bridgeRootCAs
+		bridgeRootCAsLock.RUnlock()
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			// bridge certificates are issued for the bridge id rather than a hostname, and need not list a usage
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		return err
+	}
+}
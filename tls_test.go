@@ -0,0 +1,88 @@
+package hue
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const testBridgeID = "001788fffe000000"
+
+// newTestCertificate creates a certificate with the given common name, signed by parent or self-signed when nil.
+func newTestCertificate(t *testing.T, commonName string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// restoreBridgeRootCAs resets the roots to the Signify root CA when the test finishes.
+func restoreBridgeRootCAs(t *testing.T) {
+	t.Cleanup(func() {
+		bridgeRootCAsLock.Lock()
+		defer bridgeRootCAsLock.Unlock()
+		bridgeRootCAs = mustCertPool(signifyRootCA)
+	})
+}
+
+func TestVerifyBridgeCertificateRejectsSelfSigned(t *testing.T) {
+	cert, _ := newTestCertificate(t, testBridgeID, false, nil, nil)
+	err := verifyBridgeCertificate(testBridgeID)([][]byte{cert.Raw}, nil)
+	if err == nil {
+		t.Error("self-signed certificate with the bridge id as common name was accepted, want it rejected by the Signify root CA")
+	}
+}
+
+func TestVerifyBridgeCertificateWithRootCA(t *testing.T) {
+	restoreBridgeRootCAs(t)
+	ca, caKey := newTestCertificate(t, "root-bridge", true, nil, nil)
+	err := SetBridgeRootCA(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+	if err != nil {
+		t.Fatalf("SetBridgeRootCA: %v", err)
+	}
+
+	leaf, _ := newTestCertificate(t, testBridgeID, false, ca, caKey)
+	err = verifyBridgeCertificate(testBridgeID)([][]byte{leaf.Raw}, nil)
+	if err != nil {
+		t.Errorf("certificate signed by the root CA was rejected: %v", err)
+	}
+
+	err = verifyBridgeCertificate("001788fffe111111")([][]byte{leaf.Raw}, nil)
+	if err == nil {
+		t.Error("certificate for another bridge id was accepted")
+	}
+
+	other, _ := newTestCertificate(t, testBridgeID, false, nil, nil)
+	err = verifyBridgeCertificate(testBridgeID)([][]byte{other.Raw}, nil)
+	if err == nil {
+		t.Error("self-signed certificate was accepted while a root CA is set")
+	}
+}
@@ -0,0 +1,99 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// WhitelistedUser holds the details of a user in the whitelist of the bridge.
+type WhitelistedUser struct {
+	Username    string // The username (key) of the user.
+	Name        string // DeviceType (library or executable name) given when the user was created.
+	CreateDate  Time   // Time at which the user was created. Zero when unknown.
+	LastUseDate Time   // Time at which the user was last used. Zero when unknown.
+}
+
+// Users returns the whitelisted users of the bridge.
+// The users are sorted by last use date, the most recently used user comes first.
+func (b *Bridge) Users() ([]WhitelistedUser, error) {
+	return b.UsersContext(context.Background())
+}
+
+// UsersContext is like Users, the request is bound to the given context.
+func (b *Bridge) UsersContext(ctx context.Context) ([]WhitelistedUser, error) {
+	c, err := b.FetchConfigurationContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]WhitelistedUser, 0, len(c.Whitelist))
+	for username, entry := range c.Whitelist {
+		user := WhitelistedUser{
+			Username: username,
+			Name:     entry.Name,
+		}
+		if entry.CreateDate != nil {
+			user.CreateDate = *entry.CreateDate
+		}
+		if entry.LastUseDate != nil {
+			user.LastUseDate = *entry.LastUseDate
+		}
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return time.Time(users[i].LastUseDate).After(time.Time(users[j].LastUseDate))
+	})
+	return users, nil
+}
+
+// DeleteUser removes the given username from the whitelist of the bridge.
+// An *APIError is returned when the bridge rejects the deletion, e.g. when the user does not exist.
+func (b *Bridge) DeleteUser(username string) error {
+	return b.DeleteUserContext(context.Background(), username)
+}
+
+// DeleteUserContext is like DeleteUser, the request is bound to the given context.
+func (b *Bridge) DeleteUserContext(ctx context.Context, username string) error {
+	if len(username) == 0 {
+		return errors.New("username to delete must not be empty")
+	}
+	_, err := b.sendAPIRequest(ctx, "DELETE", b.URL()+"/config/whitelist/"+username, nil)
+	return err
+}
+
+// PruneUsers deletes the users from the whitelist that were last used longer than olderThan ago,
+// and returns their usernames. The Username of the bridge itself is never deleted.
+// Users without a known last use date are judged by their create date, and kept when both are unknown.
+// When a deletion fails, the usernames deleted so far are returned together with the error.
+func (b *Bridge) PruneUsers(olderThan time.Duration) ([]string, error) {
+	return b.PruneUsersContext(context.Background(), olderThan)
+}
+
+// PruneUsersContext is like PruneUsers, the requests are bound to the given context.
+func (b *Bridge) PruneUsersContext(ctx context.Context, olderThan time.Duration) ([]string, error) {
+	users, err := b.UsersContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, current := b.credentials()
+	threshold := time.Now().Add(-olderThan)
+	removed := make([]string, 0)
+	for _, user := range users {
+		lastUse := time.Time(user.LastUseDate)
+		if lastUse.IsZero() {
+			lastUse = time.Time(user.CreateDate)
+		}
+		if user.Username == current || lastUse.IsZero() || !lastUse.Before(threshold) {
+			continue
+		}
+		err = b.DeleteUserContext(ctx, user.Username)
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, user.Username)
+	}
+	return removed, nil
+}
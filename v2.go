@@ -0,0 +1,237 @@
+package hue
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ResourceIdentifier references a resource in the v2 api.
+type ResourceIdentifier struct {
+	RID   string `json:"rid"`   // id of the resource
+	RType string `json:"rtype"` // type of the resource, e.g. “device” or “light”
+}
+
+// LightV2 holds a light resource as returned by the v2 api.
+type LightV2 struct {
+	ID       string             `json:"id"`    // id of the light resource
+	IDV1     string             `json:"id_v1"` // path of the light in the v1 api, e.g. “/lights/1”
+	Type     string             `json:"type"`  // always “light”
+	Owner    ResourceIdentifier `json:"owner"` // device that owns the light
+	Metadata struct {
+		Name      string `json:"name"`      // name of the light
+		Archetype string `json:"archetype"` // archetype of the light, e.g. “sultan_bulb”
+	} `json:"metadata"`
+	On struct {
+		On bool `json:"on"` // On/Off state of the light. On=true, Off=false
+	} `json:"on"`
+	Dimming *struct {
+		Brightness float64 `json:"brightness"` // brightness percentage, 0 is not off
+	} `json:"dimming"` // only present for dimmable lights
+	ColorTemperature *struct {
+		Mirek      *uint16 `json:"mirek"`       // color temperature in mirek (mired), nil when not in color temperature mode
+		MirekValid bool    `json:"mirek_valid"` // whether Mirek holds a valid value
+	} `json:"color_temperature"` // only present for lights supporting color temperature
+	Color *struct {
+		XY struct {
+			X float64 `json:"x"`
+			Y float64 `json:"y"`
+		} `json:"xy"` // color in CIE color space
+	} `json:"color"` // only present for color lights
+}
+
+// V2Error is returned when the v2 api reports errors.
+type V2Error struct {
+	StatusCode   int      // http status code of the response
+	Descriptions []string // descriptions of the errors
+}
+
+// Error returns the descriptions of the errors, separated by semicolons.
+func (e *V2Error) Error() string {
+	return fmt.Sprintf("bridge responded with http status %d: %s", e.StatusCode, strings.Join(e.Descriptions, "; "))
+}
+
+// v2Response is the envelope of all v2 api responses
+type v2Response struct {
+	Errors []struct {
+		Description string `json:"description"`
+	} `json:"errors"`
+	Data json.RawMessage `json:"data"`
+}
+
+// UseV2 lets the bridge use the v2 api for operations that are available in both the v1 and the v2 api.
+// The v2 api is served over https by newer bridges, the Username is used as application key.
+// The bridge certificate is verified against the Signify root CA with BridgeTLSConfig, so BridgeID must be set
+// unless InsecureSkipVerify is set.
+// Methods that exist only for the v2 api, like GetLightsV2, always use it.
+// UseV2 is safe to call while the bridge is in use.
+func (b *Bridge) UseV2() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.v2 = true
+}
+
+// usesV2 reports whether UseV2 was called
+func (b *Bridge) usesV2() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.v2
+}
+
+// GetLightsV2 returns all light resources from the v2 api.
+func (b *Bridge) GetLightsV2() ([]*LightV2, error) {
+	return b.GetLightsV2Context(context.Background())
+}
+
+// GetLightsV2Context is like GetLightsV2, the request is bound to the given context.
+func (b *Bridge) GetLightsV2Context(ctx context.Context) ([]*LightV2, error) {
+	lights := make([]*LightV2, 0)
+	err := b.v2Request(ctx, "GET", "/resource/light", nil, &lights)
+	if err != nil {
+		return nil, err
+	}
+	return lights, nil
+}
+
+// Identify lets the light breathe briefly, to find out which physical light it is.
+// With the v2 api enabled (see UseV2) the identify action of the light resource is used,
+// otherwise a single breathe cycle is requested with the alert effect.
+func (l *Light) Identify() error {
+	return l.IdentifyContext(context.Background())
+}
+
+// IdentifyContext is like Identify, the requests are bound to the given context.
+func (l *Light) IdentifyContext(ctx context.Context) error {
+	if !l.bridge.usesV2() {
+		alert := AlertSelect
+		return l.SetStateContext(ctx, LightStateChange{Alert: &alert})
+	}
+	rid, err := l.v2ID(ctx)
+	if err != nil {
+		return err
+	}
+	identify := map[string]interface{}{"identify": map[string]string{"action": "identify"}}
+	return l.bridge.v2Request(ctx, "PUT", "/resource/light/"+rid, identify, nil)
+}
+
+// v2ID returns the id of the light resource in the v2 api, matching the id of the light in the v1 api.
+func (l *Light) v2ID(ctx context.Context) (string, error) {
+	lights, err := l.bridge.GetLightsV2Context(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, light := range lights {
+		if light.IDV1 == "/lights/"+l.ID {
+			return light.ID, nil
+		}
+	}
+	return "", fmt.Errorf("light %s: %w", l.ID, ErrLightNotFound)
+}
+
+// v2URL returns the url for the given path in the v2 api.
+func (b *Bridge) v2URL(path string) string {
+	ip, _ := b.credentials()
+	return "https://" + ip + "/clip/v2" + path
+}
+
+// v2Request sends a request to the v2 api, with the application key header set.
+// When requestData is non-nil, it is sent as json body. The data of the response is decoded into v.
+func (b *Bridge) v2Request(ctx context.Context, method string, path string, requestData interface{}, v interface{}) error {
+	var body io.Reader
+	if requestData != nil {
+		requestBody, err := json.Marshal(requestData)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(requestBody)
+	}
+
+	client, err := b.v2Client()
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, method, b.v2URL(path), body)
+	if err != nil {
+		return err
+	}
+	_, username := b.credentials()
+	request.Header.Set("hue-application-key", username)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, err := b.do(clientForContext(ctx, client), request)
+	if err != nil {
+		b.logf("hue: %s %s: %v", method, b.v2URL(path), err)
+		return err
+	}
+	defer response.Body.Close()
+	b.logf("hue: %s %s: %s", method, b.v2URL(path), response.Status)
+
+	result := &v2Response{}
+	err = json.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		if response.StatusCode != http.StatusOK {
+			return &TransportError{StatusCode: response.StatusCode}
+		}
+		return err
+	}
+	if len(result.Errors) > 0 {
+		v2Error := &V2Error{StatusCode: response.StatusCode}
+		for _, e := range result.Errors {
+			v2Error.Descriptions = append(v2Error.Descriptions, e.Description)
+		}
+		return v2Error
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Data, v)
+}
+
+// v2ClientConfig holds the settings of the bridge that the client for the v2 api depends on
+type v2ClientConfig struct {
+	bridgeID           string
+	insecureSkipVerify bool
+}
+
+// v2Client returns the client used for requests to the v2 api.
+// When an HTTPClient is set on the bridge it is used, it must be able to verify the bridge certificate.
+// The Timeout of the bridge applies to either client.
+// Otherwise a client is created that verifies the bridge certificate using BridgeTLSConfig,
+// or that does not verify it at all when InsecureSkipVerify is set. The client is reused until
+// BridgeID or InsecureSkipVerify change.
+func (b *Bridge) v2Client() (*http.Client, error) {
+	if b.HTTPClient != nil {
+		return b.withTimeout(b.HTTPClient), nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	config := v2ClientConfig{bridgeID: b.BridgeID, insecureSkipVerify: b.InsecureSkipVerify}
+	if len(config.bridgeID) == 0 && !config.insecureSkipVerify {
+		return nil, errors.New("BridgeID must be set to verify the bridge certificate for the v2 api")
+	}
+	if b.v2HTTPClient == nil || b.v2ClientConfig != config {
+		tlsConfig := BridgeTLSConfig(config.bridgeID)
+		if config.insecureSkipVerify {
+			tlsConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		if b.v2HTTPClient != nil {
+			b.v2HTTPClient.CloseIdleConnections()
+		}
+		b.v2HTTPClient = &http.Client{
+			Timeout: defaultHTTPClient.Timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		}
+		b.v2ClientConfig = config
+	}
+	return b.withTimeout(b.v2HTTPClient), nil
+}
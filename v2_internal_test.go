@@ -0,0 +1,67 @@
+package hue
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestV2ClientFollowsSettings(t *testing.T) {
+	b := NewBridge("192.168.1.2")
+	_, err := b.v2Client()
+	if err == nil {
+		t.Error("v2Client without BridgeID or InsecureSkipVerify returned no error")
+	}
+
+	b.SetInsecureSkipVerify(true)
+	insecure, err := b.v2Client()
+	if err != nil {
+		t.Fatalf("v2Client with InsecureSkipVerify: %v", err)
+	}
+	again, err := b.v2Client()
+	if err != nil || again != insecure {
+		t.Errorf("v2Client with unchanged settings returned another client")
+	}
+
+	b.SetBridgeID(testBridgeID)
+	b.SetInsecureSkipVerify(false)
+	verifying, err := b.v2Client()
+	if err != nil {
+		t.Fatalf("v2Client with BridgeID: %v", err)
+	}
+	if verifying == insecure {
+		t.Fatal("v2Client kept the insecure client after InsecureSkipVerify was unset")
+	}
+	if verifying.Transport.(*http.Transport).TLSClientConfig.VerifyPeerCertificate == nil {
+		t.Error("client after InsecureSkipVerify was unset does not verify the bridge certificate")
+	}
+
+	b.SetBridgeID("001788fffe111111")
+	other, err := b.v2Client()
+	if err != nil {
+		t.Fatalf("v2Client with changed BridgeID: %v", err)
+	}
+	if other == verifying {
+		t.Error("v2Client kept the client for the previous BridgeID")
+	}
+}
+
+func TestUseV2Concurrent(t *testing.T) {
+	b := NewBridge("192.168.1.2")
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		b.UseV2()
+		b.SetBridgeID(testBridgeID)
+	}()
+	go func() {
+		defer wg.Done()
+		b.usesV2()
+		b.v2Client()
+	}()
+	wg.Wait()
+	if !b.usesV2() {
+		t.Error("usesV2 is false after UseV2")
+	}
+}